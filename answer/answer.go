@@ -0,0 +1,154 @@
+// Package answer keeps a ledger of submitted puzzle answers and the verdict the server gave each of them
+package answer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Verdict is the server's response to a submitted answer
+type Verdict string
+
+const (
+	Correct   Verdict = "correct"
+	Incorrect Verdict = "incorrect"
+	TooHigh   Verdict = "too high"
+	TooLow    Verdict = "too low"
+	// RateLimited means the answer was not checked because another was submitted too recently
+	RateLimited Verdict = "rate limited"
+	// AlreadySolved means the part was already solved, so the answer was not checked
+	AlreadySolved Verdict = "already solved"
+)
+
+// Entry is a single answer for one part of a day's puzzle
+type Entry struct {
+	Day     int     `json:"day"`
+	Part    int     `json:"part"`
+	Answer  string  `json:"answer"`
+	Verdict Verdict `json:"verdict"`
+}
+
+// Ledger is every answer given so far, correct or not, sorted by day and part
+type Ledger struct {
+	Entries []Entry
+}
+
+func Load(r io.Reader) (*Ledger, error) {
+	var l Ledger
+	if err := json.NewDecoder(r).Decode(&l.Entries); err != nil {
+		return nil, fmt.Errorf("failed to decode ledger: %w", err)
+	}
+	return &l, nil
+}
+
+// LoadFile loads the ledger at path. A missing file is an empty ledger
+func LoadFile(path string) (*Ledger, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return new(Ledger), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+func (l *Ledger) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	entries := l.Entries
+	if entries == nil {
+		entries = []Entry{}
+	}
+	return enc.Encode(entries)
+}
+
+func (l *Ledger) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := l.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Correct returns the known correct answer for a part, if there is one
+func (l *Ledger) Correct(day, part int) (string, bool) {
+	for _, e := range l.Entries {
+		if e.Day == day && e.Part == part && e.Verdict == Correct {
+			return e.Answer, true
+		}
+	}
+	return "", false
+}
+
+// Lookup returns the verdict previously given for answer, so that the same wrong answer is never submitted twice
+func (l *Ledger) Lookup(day, part int, answer string) (Verdict, bool) {
+	for _, e := range l.Entries {
+		if e.Day == day && e.Part == part && e.Answer == answer {
+			return e.Verdict, true
+		}
+	}
+	return "", false
+}
+
+// Record adds e to the ledger, replacing any previous verdict for the same answer. Verdicts that didn't check the
+// answer, such as [RateLimited], are not recorded
+func (l *Ledger) Record(e Entry) {
+	if e.Verdict == RateLimited || e.Verdict == AlreadySolved {
+		return
+	}
+	for i, existing := range l.Entries {
+		if existing.Day == e.Day && existing.Part == e.Part && existing.Answer == e.Answer {
+			l.Entries[i] = e
+			return
+		}
+	}
+	l.Entries = append(l.Entries, e)
+	sort.SliceStable(l.Entries, func(i, j int) bool {
+		a, b := l.Entries[i], l.Entries[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		return a.Part < b.Part
+	})
+}
+
+// Result compares a solver's answer to the ledger
+type Result struct {
+	Day, Part int
+	Got       string
+	// Want is empty if the correct answer is not yet known
+	Want string
+	Err  error
+}
+
+func (r Result) OK() bool {
+	return r.Err == nil && r.Want != "" && r.Got == r.Want
+}
+
+func (r Result) String() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("day %v part %v: error: %v", r.Day, r.Part, r.Err)
+	case r.Want == "":
+		return fmt.Sprintf("day %v part %v: %v (unverified)", r.Day, r.Part, r.Got)
+	case r.OK():
+		return fmt.Sprintf("day %v part %v: %v", r.Day, r.Part, r.Got)
+	default:
+		return fmt.Sprintf("day %v part %v: got %v, want %v", r.Day, r.Part, r.Got, r.Want)
+	}
+}
+
+// Check compares got, the output of solving a part, to the ledger
+func (l *Ledger) Check(day, part int, got string, err error) Result {
+	want, _ := l.Correct(day, part)
+	return Result{Day: day, Part: part, Got: got, Want: want, Err: err}
+}
@@ -0,0 +1,74 @@
+package answer
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestLedger(t *testing.T) {
+	t.Parallel()
+	var l Ledger
+	l.Record(Entry{Day: 2, Part: 1, Answer: "10", Verdict: TooLow})
+	l.Record(Entry{Day: 1, Part: 2, Answer: "5", Verdict: Correct})
+	l.Record(Entry{Day: 2, Part: 1, Answer: "20", Verdict: Correct})
+	l.Record(Entry{Day: 2, Part: 2, Answer: "7", Verdict: RateLimited})
+	if len(l.Entries) != 3 || l.Entries[0].Day != 1 {
+		t.Errorf("unexpected entries %+v", l.Entries)
+	}
+	if want, ok := l.Correct(2, 1); !ok || want != "20" {
+		t.Errorf("unexpected correct answer %v", want)
+	}
+	if _, ok := l.Correct(2, 2); ok {
+		t.Errorf("rate limited answers should not be recorded")
+	}
+	if verdict, ok := l.Lookup(2, 1, "10"); !ok || verdict != TooLow {
+		t.Errorf("unexpected verdict %v", verdict)
+	}
+
+	if result := l.Check(2, 1, "20", nil); !result.OK() {
+		t.Errorf("unexpected result %v", result)
+	}
+	if result := l.Check(2, 1, "10", nil); result.OK() {
+		t.Errorf("unexpected result %v", result)
+	}
+	if result := l.Check(2, 1, "", errors.New("bad input")); result.OK() {
+		t.Errorf("unexpected result %v", result)
+	}
+	if result := l.Check(3, 1, "1", nil); result.OK() || result.Want != "" {
+		t.Errorf("unexpected result %v", result)
+	}
+
+	var buf bytes.Buffer
+	if err := l.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Entries) != len(l.Entries) || loaded.Entries[2] != l.Entries[2] {
+		t.Errorf("ledger did not round trip %+v", loaded.Entries)
+	}
+}
+
+func TestLedgerFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "answers.json")
+	l, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Record(Entry{Day: 1, Part: 1, Answer: "1", Verdict: Correct})
+	if err := l.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	l, err = LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, ok := l.Correct(1, 1); !ok || want != "1" {
+		t.Errorf("unexpected correct answer %v", want)
+	}
+}
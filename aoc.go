@@ -2,12 +2,17 @@ package aoc
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/bits"
 	"sort"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 type Elf struct {
@@ -17,11 +22,18 @@ type Elf struct {
 
 // GetElves returns a list of all elves along with the number of nalories they are holding, sorted descending by calorie count
 func GetElves(r io.Reader) ([]Elf, error) {
+	return GetElvesContext(context.Background(), r)
+}
+
+func GetElvesContext(ctx context.Context, r io.Reader) ([]Elf, error) {
 	scanner := bufio.NewScanner(r)
 	var elves []Elf
 	var currentElf Elf
 	i := 1
 	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		text := scanner.Text()
 		if text == "" {
 			elves = append(elves, currentElf)
@@ -147,9 +159,16 @@ const (
 )
 
 func ParseStrategyGuide(r io.Reader) ([]RPSRound, error) {
+	return ParseStrategyGuideContext(context.Background(), r)
+}
+
+func ParseStrategyGuideContext(ctx context.Context, r io.Reader) ([]RPSRound, error) {
 	scanner := bufio.NewScanner(r)
 	var rounds []RPSRound
 	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		var round RPSRound
 		before, after, found := strings.Cut(scanner.Text(), " ")
 		if !found {
@@ -158,6 +177,9 @@ func ParseStrategyGuide(r io.Reader) ([]RPSRound, error) {
 		round.Opponent = NewShape(before)
 		round.Self = NewShape(after)
 		round.Outcome = NewOutcome(after)
+		if round.Opponent == UnknownShape || round.Self == UnknownShape {
+			return nil, fmt.Errorf("error parsing guide, '%v'", scanner.Text())
+		}
 		rounds = append(rounds, round)
 	}
 	if err := scanner.Err(); err != nil {
@@ -180,36 +202,41 @@ var priorityAlphabet = []byte("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVW
 
 func Priority(rucksack string) int {
 	mid := len(rucksack) / 2
-	before, after := rucksack[mid:], rucksack[:mid]
-	// alternatively, call BadgePriority with [before,after]
-	r := before[strings.IndexAny(before, after)]
-	return strings.IndexByte(string(priorityAlphabet), r) + 1
+	return BadgePriority([]string{rucksack[:mid], rucksack[mid:]})
 }
 
+// BadgePriority returns the priority of the single item common to all rucksacks, or -1 if there isn't exactly one
 func BadgePriority(rucksacks []string) int {
-	seen := make(map[rune]map[int]struct{})
-	for i, rucksack := range rucksacks {
-		for _, r := range rucksack {
-			if _, ok := seen[r]; !ok {
-				seen[r] = make(map[int]struct{})
-			}
-			seen[r][i] = struct{}{}
-		}
+	if len(rucksacks) == 0 {
+		return -1
 	}
-	// should only have one seen in all
-	for r, v := range seen {
-		if len(v) == len(rucksacks) {
-			return strings.IndexRune(string(priorityAlphabet), r) + 1
-		}
+	common := byteSetOf(rucksacks[0])
+	for _, rucksack := range rucksacks[1:] {
+		common = common.Intersection(byteSetOf(rucksack))
+	}
+	item, ok := common.Min()
+	if !ok || common.Len() != 1 {
+		return -1
 	}
-	return -1
+	return bytes.IndexByte(priorityAlphabet, item) + 1
 }
 
 func SumPriority(r io.Reader) (int, error) {
+	return SumPriorityContext(context.Background(), r)
+}
+
+func SumPriorityContext(ctx context.Context, r io.Reader) (int, error) {
 	var sum int
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
-		sum += Priority(scanner.Text())
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		priority := Priority(scanner.Text())
+		if priority < 1 {
+			return 0, fmt.Errorf("rucksack %q does not have exactly one item in both compartments", scanner.Text())
+		}
+		sum += priority
 	}
 	if err := scanner.Err(); err != nil {
 		return 0, err
@@ -218,13 +245,24 @@ func SumPriority(r io.Reader) (int, error) {
 }
 
 func SumBadgePriority(r io.Reader) (int, error) {
+	return SumBadgePriorityContext(context.Background(), r)
+}
+
+func SumBadgePriorityContext(ctx context.Context, r io.Reader) (int, error) {
 	var sum int
 	scanner := bufio.NewScanner(r)
 	var rucksacks []string
 	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
 		rucksacks = append(rucksacks, scanner.Text())
 		if len(rucksacks) == 3 {
-			sum += BadgePriority(rucksacks)
+			priority := BadgePriority(rucksacks)
+			if priority < 1 {
+				return 0, fmt.Errorf("rucksacks %q do not have exactly one badge in common", rucksacks)
+			}
+			sum += priority
 			rucksacks = nil
 			continue
 		}
@@ -232,6 +270,9 @@ func SumBadgePriority(r io.Reader) (int, error) {
 	if err := scanner.Err(); err != nil {
 		return 0, err
 	}
+	if len(rucksacks) != 0 {
+		return 0, fmt.Errorf("%v rucksacks left over, should be in groups of 3", len(rucksacks))
+	}
 	return sum, nil
 }
 
@@ -244,6 +285,29 @@ func (a Assignment) FullyContains(b Assignment) bool {
 	return b.Start <= a.End && b.Start >= a.Start && b.End <= a.End && b.End >= a.Start
 }
 
+// Len returns the number of sections from Start to End inclusive
+func (a Assignment) Len() int {
+	return a.End - a.Start + 1
+}
+
+// MergeAssignments sorts assignments by Start and merges any that overlap or touch, so that every section covered by
+// assignments is covered by exactly one of the results. assignments itself is not modified
+func MergeAssignments(assignments []Assignment) []Assignment {
+	sorted := append([]Assignment(nil), assignments...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+	var merged []Assignment
+	for _, a := range sorted {
+		if last := len(merged) - 1; last >= 0 && a.Start <= merged[last].End+1 {
+			merged[last].End = maxInt(merged[last].End, a.End)
+			continue
+		}
+		merged = append(merged, a)
+	}
+	return merged
+}
+
 type AssignmentPair struct {
 	Left  Assignment
 	Right Assignment
@@ -282,14 +346,24 @@ func (p AssignmentPair) HasOverlappingSections() bool {
 }
 
 func ParseAssignments(r io.Reader) ([]AssignmentPair, error) {
+	return ParseAssignmentsContext(context.Background(), r)
+}
+
+func ParseAssignmentsContext(ctx context.Context, r io.Reader) ([]AssignmentPair, error) {
 	scanner := bufio.NewScanner(r)
 	var assignmentPairs []AssignmentPair
 	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		var pair AssignmentPair
 		count, err := fmt.Sscanf(scanner.Text(), "%d-%d,%d-%d", &(pair.Left.Start), &(pair.Left.End), &(pair.Right.Start), &(pair.Right.End))
 		if count != 4 || err != nil {
 			return nil, fmt.Errorf("failed to scan assignment pair count: %v, err: %v", count, err)
 		}
+		if pair.Left.Start > pair.Left.End || pair.Right.Start > pair.Right.End {
+			return nil, fmt.Errorf("assignment pair %v has a section that ends before it starts", pair)
+		}
 		assignmentPairs = append(assignmentPairs, pair)
 	}
 	if err := scanner.Err(); err != nil {
@@ -322,36 +396,240 @@ type Step struct {
 	Amount, From, To int
 }
 
-type Stack []string
+// Stack is a last in, first out stack. The top of the stack is the end of the slice. The zero value is an empty stack
+type Stack[T any] []T
 
-func (s *Stack) Push(st ...string) {
-	*s = append(*s, st...)
+func (s *Stack[T]) Push(elems ...T) {
+	*s = append(*s, elems...)
 }
 
-func (s *Stack) Peek() string {
-	i := len(*s) - 1
-	return (*s)[i]
+// Peek returns the top of the stack. It panics if the stack is empty, see [Stack.TryPeek] for a safe alternative
+func (s *Stack[T]) Peek() T {
+	elem, ok := s.TryPeek()
+	if !ok {
+		panic("peek on empty stack")
+	}
+	return elem
 }
 
-func (s *Stack) Pop() string {
-	i := len(*s) - 1
-	elem := (*s)[i]
-	*s = (*s)[:i]
+func (s *Stack[T]) TryPeek() (T, bool) {
+	if len(*s) == 0 {
+		var zero T
+		return zero, false
+	}
+	return (*s)[len(*s)-1], true
+}
+
+// Pop removes and returns the top of the stack. It panics if the stack is empty, see [Stack.TryPop] for a safe alternative
+func (s *Stack[T]) Pop() T {
+	elem, ok := s.TryPop()
+	if !ok {
+		panic("pop on empty stack")
+	}
 	return elem
 }
 
-func (s *Stack) PopN(amount int) []string {
+func (s *Stack[T]) TryPop() (T, bool) {
+	elem, ok := s.TryPeek()
+	if !ok {
+		return elem, false
+	}
+	var zero T
+	(*s)[len(*s)-1] = zero
+	*s = (*s)[:len(*s)-1]
+	return elem, true
+}
+
+// PeekN returns a copy of the top amount elements, in the order they were pushed
+func (s *Stack[T]) PeekN(amount int) []T {
+	elems, ok := s.TryPeekN(amount)
+	if !ok {
+		panic(fmt.Sprintf("peek of %v on stack of %v", amount, len(*s)))
+	}
+	return elems
+}
+
+func (s *Stack[T]) TryPeekN(amount int) ([]T, bool) {
+	if amount < 0 || amount > len(*s) {
+		return nil, false
+	}
+	elems := make([]T, amount)
+	copy(elems, (*s)[len(*s)-amount:])
+	return elems, true
+}
+
+// PopN removes the top amount elements and returns them in the order they were pushed, so that pushing them onto
+// another stack preserves their order. The returned slice does not share memory with the stack
+func (s *Stack[T]) PopN(amount int) []T {
+	elems, ok := s.TryPopN(amount)
+	if !ok {
+		panic(fmt.Sprintf("pop of %v on stack of %v", amount, len(*s)))
+	}
+	return elems
+}
+
+func (s *Stack[T]) TryPopN(amount int) ([]T, bool) {
+	elems, ok := s.TryPeekN(amount)
+	if !ok {
+		return nil, false
+	}
 	i := len(*s) - amount
-	elems := (*s)[i:]
+	var zero T
+	for j := i; j < len(*s); j++ {
+		(*s)[j] = zero
+	}
 	*s = (*s)[:i]
+	return elems, true
+}
+
+// PopNReversed is like [Stack.PopN], but returns the elements in the order they were popped, top first
+func (s *Stack[T]) PopNReversed(amount int) []T {
+	elems := s.PopN(amount)
+	for i, j := 0, len(elems)-1; i < j; i, j = i+1, j-1 {
+		elems[i], elems[j] = elems[j], elems[i]
+	}
 	return elems
 }
 
-func (s *Stack) Len() int {
+func (s *Stack[T]) Len() int {
 	return len(*s)
 }
 
-func ParseStacksAndSteps(r io.Reader) ([]*Stack, []Step, error) {
+func (s *Stack[T]) Clone() *Stack[T] {
+	clone := make(Stack[T], len(*s))
+	copy(clone, *s)
+	return &clone
+}
+
+// Deque is a double ended queue backed by a ring buffer. The zero value is an empty deque
+type Deque[T any] struct {
+	buf  []T
+	head int
+	len  int
+}
+
+func (d *Deque[T]) Len() int {
+	return d.len
+}
+
+func (d *Deque[T]) grow() {
+	if d.len < len(d.buf) {
+		return
+	}
+	size := 2 * len(d.buf)
+	if size == 0 {
+		size = 8
+	}
+	buf := make([]T, size)
+	for i := 0; i < d.len; i++ {
+		buf[i] = d.buf[(d.head+i)%len(d.buf)]
+	}
+	d.buf = buf
+	d.head = 0
+}
+
+func (d *Deque[T]) PushBack(elems ...T) {
+	for _, e := range elems {
+		d.grow()
+		d.buf[(d.head+d.len)%len(d.buf)] = e
+		d.len++
+	}
+}
+
+func (d *Deque[T]) PushFront(elems ...T) {
+	for _, e := range elems {
+		d.grow()
+		d.head = (d.head - 1 + len(d.buf)) % len(d.buf)
+		d.buf[d.head] = e
+		d.len++
+	}
+}
+
+// At returns the i'th element from the front. It panics if i is out of range
+func (d *Deque[T]) At(i int) T {
+	if i < 0 || i >= d.len {
+		panic(fmt.Sprintf("index %v out of range of deque of %v", i, d.len))
+	}
+	return d.buf[(d.head+i)%len(d.buf)]
+}
+
+func (d *Deque[T]) TryFront() (T, bool) {
+	if d.len == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.At(0), true
+}
+
+func (d *Deque[T]) TryBack() (T, bool) {
+	if d.len == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.At(d.len - 1), true
+}
+
+func (d *Deque[T]) TryPopFront() (T, bool) {
+	elem, ok := d.TryFront()
+	if !ok {
+		return elem, false
+	}
+	var zero T
+	d.buf[d.head] = zero
+	d.head = (d.head + 1) % len(d.buf)
+	d.len--
+	return elem, true
+}
+
+func (d *Deque[T]) TryPopBack() (T, bool) {
+	elem, ok := d.TryBack()
+	if !ok {
+		return elem, false
+	}
+	var zero T
+	d.buf[(d.head+d.len-1)%len(d.buf)] = zero
+	d.len--
+	return elem, true
+}
+
+// PopFront removes and returns the front of the deque. It panics if the deque is empty
+func (d *Deque[T]) PopFront() T {
+	elem, ok := d.TryPopFront()
+	if !ok {
+		panic("pop on empty deque")
+	}
+	return elem
+}
+
+// PopBack removes and returns the back of the deque. It panics if the deque is empty
+func (d *Deque[T]) PopBack() T {
+	elem, ok := d.TryPopBack()
+	if !ok {
+		panic("pop on empty deque")
+	}
+	return elem
+}
+
+// Slice returns a copy of the elements from front to back
+func (d *Deque[T]) Slice() []T {
+	elems := make([]T, d.len)
+	for i := range elems {
+		elems[i] = d.At(i)
+	}
+	return elems
+}
+
+func (d *Deque[T]) Clone() *Deque[T] {
+	clone := new(Deque[T])
+	clone.PushBack(d.Slice()...)
+	return clone
+}
+
+func ParseStacksAndSteps(r io.Reader) ([]*Stack[string], []Step, error) {
+	return ParseStacksAndStepsContext(context.Background(), r)
+}
+
+func ParseStacksAndStepsContext(ctx context.Context, r io.Reader) ([]*Stack[string], []Step, error) {
 	b, err := io.ReadAll(r)
 	if err != nil {
 		return nil, nil, err
@@ -362,25 +640,28 @@ func ParseStacksAndSteps(r io.Reader) ([]*Stack, []Step, error) {
 		return nil, nil, errors.New("failed to split stacks and steps")
 	}
 
-	var stacks []*Stack
+	var stacks []*Stack[string]
 	var steps []Step
 
 	stacksStrings := strings.Split(stackHalf, "\n")
 	// count the number of integers in the final row
 	width := len(strings.Fields(stacksStrings[len(stacksStrings)-1]))
-	stacks = make([]*Stack, width)
+	stacks = make([]*Stack[string], width)
 	for i := range stacks {
-		stacks[i] = new(Stack)
+		stacks[i] = new(Stack[string])
 	}
 
 	// reversed, skip the integer row as well
 	for i := len(stacksStrings) - 2; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
 		stackString := stacksStrings[i]
 		var current string
 		for i := 0; i < width; i++ {
 			// [Z]\w is 4 characters, but the final one is only 3. Avoid a nil pointer error
 			if len(stackString) < 4 {
-				current = stackString
+				current, stackString = stackString, ""
 			} else {
 				current, stackString = stackString[:4], stackString[4:]
 			}
@@ -393,21 +674,52 @@ func ParseStacksAndSteps(r io.Reader) ([]*Stack, []Step, error) {
 		}
 	}
 
+	// track the height of each stack so that steps can't move more crates than there are
+	heights := make([]int, width)
+	for i, stack := range stacks {
+		heights[i] = stack.Len()
+	}
 	stepsStrings := strings.Split(stepHalf, "\n")
-	for _, stepString := range stepsStrings {
+	for line, stepString := range stepsStrings {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		if stepString == "" {
+			continue
+		}
 		var step Step
-		fmt.Sscanf(stepString, "move %d from %d to %d", &step.Amount, &step.From, &step.To)
+		n, err := fmt.Sscanf(stepString, "move %d from %d to %d", &step.Amount, &step.From, &step.To)
+		if n != 3 || err != nil {
+			return nil, nil, fmt.Errorf("failed to scan step %v %q: %v", line+1, stepString, err)
+		}
 		// to zero index it all
 		step.From--
 		step.To--
+		if step.From < 0 || step.From >= width || step.To < 0 || step.To >= width {
+			return nil, nil, fmt.Errorf("step %v %q refers to a stack that doesn't exist", line+1, stepString)
+		}
+		if step.Amount < 0 || step.Amount > heights[step.From] {
+			return nil, nil, fmt.Errorf("step %v %q moves %v crates from a stack of %v", line+1, stepString, step.Amount, heights[step.From])
+		}
+		heights[step.From] -= step.Amount
+		heights[step.To] += step.Amount
 		steps = append(steps, step)
 	}
 
 	return stacks, steps, nil
 }
 
-func ProcessSteps(stacks []*Stack, steps []Step) []*Stack {
+func ProcessSteps(stacks []*Stack[string], steps []Step) []*Stack[string] {
+	stacks, _ = ProcessStepsContext(context.Background(), stacks, steps)
+	return stacks
+}
+
+// ProcessStepsContext stops between steps if ctx is cancelled, leaving the stacks partially processed
+func ProcessStepsContext(ctx context.Context, stacks []*Stack[string], steps []Step) ([]*Stack[string], error) {
 	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return stacks, err
+		}
 		for i := 0; i < step.Amount; i++ {
 			from := stacks[step.From]
 			to := stacks[step.To]
@@ -415,66 +727,394 @@ func ProcessSteps(stacks []*Stack, steps []Step) []*Stack {
 			to.Push(crate)
 		}
 	}
+	return stacks, nil
+}
+func ProcessSteps9001(stacks []*Stack[string], steps []Step) []*Stack[string] {
+	stacks, _ = ProcessSteps9001Context(context.Background(), stacks, steps)
 	return stacks
 }
-func ProcessSteps9001(stacks []*Stack, steps []Step) []*Stack {
+
+func ProcessSteps9001Context(ctx context.Context, stacks []*Stack[string], steps []Step) ([]*Stack[string], error) {
 	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return stacks, err
+		}
 		from := stacks[step.From]
 		to := stacks[step.To]
 		crates := from.PopN(step.Amount)
 		to.Push(crates...)
 	}
-	return stacks
+	return stacks, nil
 }
-func SumTopOfStacks(stacks []*Stack) string {
+
+// EmptyStackTop stands in for the top crate of an empty stack in [SumTopOfStacks], so the letters after it stay in
+// place
+const EmptyStackTop = " "
+
+// SumTopOfStacks reads the top crate of every stack in order, with EmptyStackTop for any stack that is empty
+func SumTopOfStacks(stacks []*Stack[string]) string {
 	var sum []string
 	for _, stack := range stacks {
-		sum = append(sum, stack.Peek())
+		top, ok := stack.TryPeek()
+		if !ok {
+			top = EmptyStackTop
+		}
+		sum = append(sum, top)
 	}
 	return strings.Join(sum, "")
 }
 
+// Set is an unordered collection of unique elements. The zero value is a nil map, so use [NewSet] before calling Put
 type Set[T comparable] map[T]struct{}
 
-func NewSet[T comparable]() *Set[T] {
-	var set Set[T] = make(map[T]struct{})
-	return &set
+// NewSet returns a set containing elems
+func NewSet[T comparable](elems ...T) Set[T] {
+	set := make(Set[T], len(elems))
+	set.Put(elems...)
+	return set
 }
 
-func (s *Set[T]) Put(st ...T) {
-	for _, c := range st {
-		(*s)[c] = struct{}{}
+func (s Set[T]) Put(elems ...T) {
+	for _, e := range elems {
+		s[e] = struct{}{}
 	}
 }
 
-func (s *Set[T]) Clear() {
-	for k := range *s {
-		delete(*s, k)
+func (s Set[T]) Delete(elems ...T) {
+	for _, e := range elems {
+		delete(s, e)
 	}
 }
 
-func (s *Set[T]) Len() int {
-	return len(*s)
+func (s Set[T]) Contains(e T) bool {
+	_, ok := s[e]
+	return ok
 }
 
-func communicationDevice(r io.Reader, signalLength int) int {
-	reader := bufio.NewReaderSize(r, 14)
-	set := NewSet[byte]()
-	var i = 0
+func (s Set[T]) Clear() {
+	for k := range s {
+		delete(s, k)
+	}
+}
+
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+func (s Set[T]) Clone() Set[T] {
+	clone := make(Set[T], len(s))
+	for e := range s {
+		clone[e] = struct{}{}
+	}
+	return clone
+}
+
+// Union returns a new set of the elements in s or in other
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	union := s.Clone()
+	for e := range other {
+		union[e] = struct{}{}
+	}
+	return union
+}
+
+// Intersection returns a new set of the elements in both s and other
+func (s Set[T]) Intersection(other Set[T]) Set[T] {
+	small, large := s, other
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+	intersection := make(Set[T])
+	for e := range small {
+		if large.Contains(e) {
+			intersection[e] = struct{}{}
+		}
+	}
+	return intersection
+}
+
+// Difference returns a new set of the elements in s but not in other
+func (s Set[T]) Difference(other Set[T]) Set[T] {
+	difference := make(Set[T])
+	for e := range s {
+		if !other.Contains(e) {
+			difference[e] = struct{}{}
+		}
+	}
+	return difference
+}
+
+// SymmetricDifference returns a new set of the elements in exactly one of s and other
+func (s Set[T]) SymmetricDifference(other Set[T]) Set[T] {
+	difference := s.Difference(other)
+	for e := range other {
+		if !s.Contains(e) {
+			difference[e] = struct{}{}
+		}
+	}
+	return difference
+}
+
+// IsSubset reports whether every element of s is also in other
+func (s Set[T]) IsSubset(other Set[T]) bool {
+	if len(s) > len(other) {
+		return false
+	}
+	for e := range s {
+		if !other.Contains(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Set[T]) IsSuperset(other Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+func (s Set[T]) Equal(other Set[T]) bool {
+	return len(s) == len(other) && s.IsSubset(other)
+}
+
+// Slice returns the elements of s in an unspecified order
+func (s Set[T]) Slice() []T {
+	elems := make([]T, 0, len(s))
+	for e := range s {
+		elems = append(elems, e)
+	}
+	return elems
+}
+
+// SortedFunc returns the elements of s sorted by less
+func (s Set[T]) SortedFunc(less func(a, b T) bool) []T {
+	elems := s.Slice()
+	sort.Slice(elems, func(i, j int) bool {
+		return less(elems[i], elems[j])
+	})
+	return elems
+}
+
+// Ordered is the set of types that support the < operator
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// Sorted returns the elements of s in ascending order
+func Sorted[T Ordered](s Set[T]) []T {
+	return s.SortedFunc(func(a, b T) bool {
+		return a < b
+	})
+}
+
+// TopK returns the k largest elements according to less, largest first. It keeps a min heap of the best k seen so far,
+// so it runs in O(n log k) without sorting or modifying elems
+func TopK[T any](elems []T, k int, less func(a, b T) bool) []T {
+	if k <= 0 {
+		return nil
+	}
+	heap := make([]T, 0, minInt(k, len(elems)))
+	for _, e := range elems {
+		if len(heap) < k {
+			heap = append(heap, e)
+			siftUp(heap, len(heap)-1, less)
+		} else if less(heap[0], e) {
+			heap[0] = e
+			siftDown(heap, 0, less)
+		}
+	}
+	// pop the smallest to the back until the heap is sorted largest first
+	for end := len(heap) - 1; end > 0; end-- {
+		heap[0], heap[end] = heap[end], heap[0]
+		siftDown(heap[:end], 0, less)
+	}
+	return heap
+}
+
+func siftUp[T any](heap []T, i int, less func(a, b T) bool) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !less(heap[i], heap[parent]) {
+			return
+		}
+		heap[i], heap[parent] = heap[parent], heap[i]
+		i = parent
+	}
+}
+
+func siftDown[T any](heap []T, i int, less func(a, b T) bool) {
 	for {
-		b, err := reader.Peek(signalLength)
-		if err != nil {
-			return -1
+		smallest := i
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child < len(heap) && less(heap[child], heap[smallest]) {
+				smallest = child
+			}
+		}
+		if smallest == i {
+			return
+		}
+		heap[i], heap[smallest] = heap[smallest], heap[i]
+		i = smallest
+	}
+}
+
+// ByteSet is a fixed size bitset over all 256 byte values, with the same API as [Set]. The zero value is an empty set,
+// and none of its operations allocate
+type ByteSet [4]uint64
+
+func NewByteSet(elems ...byte) ByteSet {
+	var set ByteSet
+	set.Put(elems...)
+	return set
+}
+
+func (s *ByteSet) Put(elems ...byte) {
+	for _, e := range elems {
+		s[e/64] |= 1 << (e % 64)
+	}
+}
+
+func (s *ByteSet) Delete(elems ...byte) {
+	for _, e := range elems {
+		s[e/64] &^= 1 << (e % 64)
+	}
+}
+
+func (s ByteSet) Contains(e byte) bool {
+	return s[e/64]&(1<<(e%64)) != 0
+}
+
+func (s *ByteSet) Clear() {
+	*s = ByteSet{}
+}
+
+func (s ByteSet) Len() int {
+	var n int
+	for _, word := range s {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+func (s ByteSet) Clone() ByteSet {
+	return s
+}
+
+func (s ByteSet) Union(other ByteSet) ByteSet {
+	for i := range s {
+		s[i] |= other[i]
+	}
+	return s
+}
+
+func (s ByteSet) Intersection(other ByteSet) ByteSet {
+	for i := range s {
+		s[i] &= other[i]
+	}
+	return s
+}
+
+func (s ByteSet) Difference(other ByteSet) ByteSet {
+	for i := range s {
+		s[i] &^= other[i]
+	}
+	return s
+}
+
+func (s ByteSet) SymmetricDifference(other ByteSet) ByteSet {
+	for i := range s {
+		s[i] ^= other[i]
+	}
+	return s
+}
+
+func (s ByteSet) IsSubset(other ByteSet) bool {
+	return s.Difference(other) == ByteSet{}
+}
+
+func (s ByteSet) IsSuperset(other ByteSet) bool {
+	return other.IsSubset(s)
+}
+
+func (s ByteSet) Equal(other ByteSet) bool {
+	return s == other
+}
+
+// byteSetOf returns the set of bytes in str without converting it to a []byte
+func byteSetOf(str string) ByteSet {
+	var set ByteSet
+	for i := 0; i < len(str); i++ {
+		set.Put(str[i])
+	}
+	return set
+}
+
+// Min returns the smallest element of s, and false if s is empty
+func (s ByteSet) Min() (byte, bool) {
+	for i, word := range s {
+		if word != 0 {
+			return byte(i*64 + bits.TrailingZeros64(word)), true
+		}
+	}
+	return 0, false
+}
+
+// Slice returns the elements of s in ascending order
+func (s ByteSet) Slice() []byte {
+	elems := make([]byte, 0, s.Len())
+	for i, word := range s {
+		for word != 0 {
+			elems = append(elems, byte(i*64+bits.TrailingZeros64(word)))
+			word &= word - 1
+		}
+	}
+	return elems
+}
+
+func communicationDevice(r io.Reader, signalLength int) int {
+	start, _ := communicationDeviceContext(context.Background(), r, signalLength)
+	return start
+}
+
+// checkEvery is how many iterations hot loops go between checking for cancellation
+const checkEvery = 1 << 12
+
+func communicationDeviceContext(ctx context.Context, r io.Reader, signalLength int) (int, error) {
+	start := -1
+	err := readSignal(ctx, r, signalLength, func(i int, window []byte) bool {
+		if set := NewByteSet(window...); set.Len() == signalLength {
+			start = i + signalLength
+			return true
+		}
+		return false
+	})
+	return start, err
+}
+
+// readSignal streams r through a window of size bytes, sliding it one byte at a time, and calls f with the offset
+// and contents of each window until f returns true or r runs out. The window is only valid until f returns. Running
+// out of input is not an error, but any other read error is returned
+func readSignal(ctx context.Context, r io.Reader, size int, f func(i int, window []byte) bool) error {
+	reader := bufio.NewReaderSize(r, size)
+	for i := 0; ; i++ {
+		if i%checkEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 		}
-		set.Put(b...)
-		if set.Len() == signalLength {
-			return i + signalLength
+		window, err := reader.Peek(size)
+		if errors.Is(err, io.EOF) {
+			return nil
 		}
-		set.Clear()
-		i++
-		_, err = reader.Discard(1)
 		if err != nil {
-			return -1
+			return err
+		}
+		if f(i, window) {
+			return nil
+		}
+		if _, err := reader.Discard(1); err != nil {
+			return err
 		}
 	}
 }
@@ -486,6 +1126,14 @@ func StartOfMessage(r io.Reader) int {
 	return communicationDevice(r, 14)
 }
 
+// StartOfPacketContext returns -1 and a nil error if there is no start of packet
+func StartOfPacketContext(ctx context.Context, r io.Reader) (int, error) {
+	return communicationDeviceContext(ctx, r, 4)
+}
+func StartOfMessageContext(ctx context.Context, r io.Reader) (int, error) {
+	return communicationDeviceContext(ctx, r, 14)
+}
+
 type File struct {
 	Parent   *File
 	Children []*File
@@ -520,13 +1168,26 @@ func (f *File) Size() int {
 }
 
 func ParseFS(r io.Reader) (*File, error) {
+	return ParseFSContext(context.Background(), r)
+}
+
+func ParseFSContext(ctx context.Context, r io.Reader) (*File, error) {
 	scanner := bufio.NewScanner(r)
 	root := &File{Name: "/", size: -1}
 	current := root
+	// children indexes every directory's entries by name, so neither cd nor ls scans the directory
+	children := map[*File]map[string]*File{root: {}}
 
-	// skip initial cd to /
-	scanner.Scan()
+	if !scanner.Scan() || scanner.Text() != "$ cd /" {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("transcript must start with $ cd /")
+	}
 	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		before, after, found := strings.Cut(scanner.Text(), " ")
 		if !found {
 			return nil, errors.New("bad cut")
@@ -536,38 +1197,57 @@ func ParseFS(r io.Reader) (*File, error) {
 				continue
 			}
 
-			if after[:2] == "cd" {
-				location := after[3:]
-				if location == ".." {
-					current = current.Parent
+			if strings.HasPrefix(after, "cd ") {
+				location := strings.TrimPrefix(after, "cd ")
+				switch location {
+				case "/":
+					current = root
 					continue
-				}
-				found := false
-				for _, f := range current.Children {
-					if f.Name == location {
-						current = f
-						found = true
-						break
+				case "..":
+					if current.Parent == nil {
+						return nil, errors.New("failed to cd above /")
 					}
+					current = current.Parent
+					continue
 				}
-				if !found {
+				dir, ok := children[current][location]
+				if !ok || !dir.IsDir() {
 					return nil, fmt.Errorf("failed to cd into %v", location)
 				}
+				current = dir
 				continue
 			}
 			return nil, fmt.Errorf("unhandled command %v", after)
 		}
 
 		// in LS
+		var child *File
+		if after == ".." || after == "/" {
+			return nil, fmt.Errorf("invalid name %v", after)
+		}
 		if before == "dir" {
-			current.Children = append(current.Children, &File{Parent: current, Name: after, size: -1})
-
+			child = &File{Parent: current, Name: after, size: -1}
 		} else {
 			i, err := strconv.Atoi(before)
 			if err != nil {
 				return nil, err
 			}
-			current.Children = append(current.Children, &File{Parent: current, Name: after, size: i})
+			if i < 0 {
+				return nil, fmt.Errorf("negative size %v for %v", i, after)
+			}
+			child = &File{Parent: current, Name: after, size: i}
+		}
+		// listing the same directory twice is harmless, but the listings must agree
+		if f, ok := children[current][child.Name]; ok {
+			if f.size != child.size {
+				return nil, fmt.Errorf("conflicting entries for %v", child.Name)
+			}
+			continue
+		}
+		current.Children = append(current.Children, child)
+		children[current][child.Name] = child
+		if child.IsDir() {
+			children[child] = map[string]*File{}
 		}
 	}
 	if err := scanner.Err(); err != nil {
@@ -576,6 +1256,89 @@ func ParseFS(r io.Reader) (*File, error) {
 	return root, nil
 }
 
+// NewDir returns an empty directory named name, ready to have children added with [File.Add]
+func NewDir(name string) *File {
+	return &File{Name: name, size: -1}
+}
+
+// NewFile returns a regular file named name of the given size
+func NewFile(name string, size int) *File {
+	return &File{Name: name, size: size}
+}
+
+// IsDir reports whether f is a directory
+func (f *File) IsDir() bool {
+	return f.size == -1
+}
+
+// Add appends children to the directory f, setting their Parent
+func (f *File) Add(children ...*File) {
+	for _, c := range children {
+		c.Parent = f
+	}
+	f.Children = append(f.Children, children...)
+}
+
+// WriteTranscript is the inverse of ParseFS. It writes a canonical `$ cd` / `$ ls` transcript of the tree rooted at
+// root to w. Every directory is listed exactly once, depth first, and is always left with a `$ cd ..`. Trees ParseFS
+// can't read back are an error, and nothing is written for them
+func WriteTranscript(w io.Writer, root *File) error {
+	if !root.IsDir() {
+		return fmt.Errorf("root %v is not a directory", root.Name)
+	}
+	if err := checkTranscript(root); err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "$ cd /")
+	writeTranscript(bw, root)
+	return bw.Flush()
+}
+
+// checkTranscript checks every entry under dir has a name ParseFS accepts, unique within its directory, and that only
+// directories have children
+func checkTranscript(dir *File) error {
+	names := NewSet[string]()
+	for _, c := range dir.Children {
+		switch {
+		case c.Name == ".." || c.Name == "/" || strings.Contains(c.Name, "\n") || strings.HasSuffix(c.Name, "\r"):
+			return fmt.Errorf("invalid name %q in %v", c.Name, dir.Name)
+		case names.Contains(c.Name):
+			return fmt.Errorf("duplicate name %v in %v", c.Name, dir.Name)
+		case !c.IsDir() && c.size < 0:
+			return fmt.Errorf("negative size %v for %v", c.size, c.Name)
+		case !c.IsDir() && len(c.Children) > 0:
+			return fmt.Errorf("file %v has children", c.Name)
+		}
+		names.Put(c.Name)
+		if c.IsDir() {
+			if err := checkTranscript(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeTranscript(w *bufio.Writer, dir *File) {
+	fmt.Fprintln(w, "$ ls")
+	for _, c := range dir.Children {
+		if c.IsDir() {
+			fmt.Fprintf(w, "dir %v\n", c.Name)
+		} else {
+			fmt.Fprintf(w, "%v %v\n", c.size, c.Name)
+		}
+	}
+	for _, c := range dir.Children {
+		if !c.IsDir() {
+			continue
+		}
+		fmt.Fprintf(w, "$ cd %v\n", c.Name)
+		writeTranscript(w, c)
+		fmt.Fprintln(w, "$ cd ..")
+	}
+}
+
 func SumDirSize(dirs []*File) int {
 	var less []*File
 	for _, d := range dirs {
@@ -607,90 +1370,2566 @@ func SmallestDirToDelete(root *File) int {
 	return dirs[0].Size()
 }
 
+// Point is a location on a 2D grid. Y increases downward, matching the order lines are read from input
 type Point struct {
 	X, Y int
 }
 
-func ParseGrid(r io.Reader) (map[Point]int, error) {
+func (p Point) Add(q Point) Point {
+	return Point{p.X + q.X, p.Y + q.Y}
+}
+
+func (p Point) Sub(q Point) Point {
+	return Point{p.X - q.X, p.Y - q.Y}
+}
+
+func (p Point) Scale(k int) Point {
+	return Point{p.X * k, p.Y * k}
+}
+
+// Manhattan returns the taxicab distance between p and q
+func (p Point) Manhattan(q Point) int {
+	return abs(p.X-q.X) + abs(p.Y-q.Y)
+}
+
+// Chebyshev returns the number of king moves between p and q
+func (p Point) Chebyshev(q Point) int {
+	return maxInt(abs(p.X-q.X), abs(p.Y-q.Y))
+}
+
+// Neighbors4 returns the orthogonal neighbors of p, in the order up, right, down, left
+func (p Point) Neighbors4() [4]Point {
+	return [4]Point{p.Add(Up.Delta()), p.Add(Right.Delta()), p.Add(Down.Delta()), p.Add(Left.Delta())}
+}
+
+// Neighbors8 returns the orthogonal and diagonal neighbors of p, clockwise starting from up
+func (p Point) Neighbors8() [8]Point {
+	return [8]Point{
+		{p.X, p.Y - 1}, {p.X + 1, p.Y - 1}, {p.X + 1, p.Y}, {p.X + 1, p.Y + 1},
+		{p.X, p.Y + 1}, {p.X - 1, p.Y + 1}, {p.X - 1, p.Y}, {p.X - 1, p.Y - 1},
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Direction is one of the four orthogonal directions, in clockwise order
+type Direction int
+
+const (
+	Up Direction = iota
+	Right
+	Down
+	Left
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Up:
+		return "Up"
+	case Right:
+		return "Right"
+	case Down:
+		return "Down"
+	case Left:
+		return "Left"
+	default:
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+}
+
+// Delta returns the Point that moves one step in direction d. It panics if d is not one of the four directions
+func (d Direction) Delta() Point {
+	switch d {
+	case Up:
+		return Point{0, -1}
+	case Right:
+		return Point{1, 0}
+	case Down:
+		return Point{0, 1}
+	case Left:
+		return Point{-1, 0}
+	default:
+		panic(fmt.Sprintf("invalid direction %v", d))
+	}
+}
+
+// mustBeValid panics if d is not one of the four directions, rather than letting arithmetic on it wrap to a valid one
+func (d Direction) mustBeValid() {
+	if d < Up || d > Left {
+		panic(fmt.Sprintf("invalid direction %v", d))
+	}
+}
+
+// RotateRight turns d 90 degrees clockwise. It panics if d is not one of the four directions
+func (d Direction) RotateRight() Direction {
+	d.mustBeValid()
+	return (d + 1) % 4
+}
+
+// RotateLeft turns d 90 degrees counterclockwise. It panics if d is not one of the four directions
+func (d Direction) RotateLeft() Direction {
+	d.mustBeValid()
+	return (d + 3) % 4
+}
+
+// Opposite turns d 180 degrees. It panics if d is not one of the four directions
+func (d Direction) Opposite() Direction {
+	d.mustBeValid()
+	return (d + 2) % 4
+}
+
+// Rect is an inclusive bounding box
+type Rect struct {
+	Min, Max Point
+}
+
+// BoundingBox returns the smallest Rect containing every point. The zero Rect is returned if there are no points
+func BoundingBox(points ...Point) Rect {
+	if len(points) == 0 {
+		return Rect{}
+	}
+	r := Rect{points[0], points[0]}
+	for _, p := range points[1:] {
+		r = r.Extend(p)
+	}
+	return r
+}
+
+// Extend returns the smallest Rect containing both r and p
+func (r Rect) Extend(p Point) Rect {
+	return Rect{
+		Min: Point{minInt(r.Min.X, p.X), minInt(r.Min.Y, p.Y)},
+		Max: Point{maxInt(r.Max.X, p.X), maxInt(r.Max.Y, p.Y)},
+	}
+}
+
+func (r Rect) Contains(p Point) bool {
+	return p.X >= r.Min.X && p.X <= r.Max.X && p.Y >= r.Min.Y && p.Y <= r.Max.Y
+}
+
+func (r Rect) Width() int {
+	return r.Max.X - r.Min.X + 1
+}
+
+func (r Rect) Height() int {
+	return r.Max.Y - r.Min.Y + 1
+}
+
+// Point3 is the 3D counterpart of Point
+type Point3 struct {
+	X, Y, Z int
+}
+
+func (p Point3) Add(q Point3) Point3 {
+	return Point3{p.X + q.X, p.Y + q.Y, p.Z + q.Z}
+}
+
+func (p Point3) Sub(q Point3) Point3 {
+	return Point3{p.X - q.X, p.Y - q.Y, p.Z - q.Z}
+}
+
+func (p Point3) Scale(k int) Point3 {
+	return Point3{p.X * k, p.Y * k, p.Z * k}
+}
+
+func (p Point3) Manhattan(q Point3) int {
+	return abs(p.X-q.X) + abs(p.Y-q.Y) + abs(p.Z-q.Z)
+}
+
+func (p Point3) Chebyshev(q Point3) int {
+	return maxInt(abs(p.X-q.X), maxInt(abs(p.Y-q.Y), abs(p.Z-q.Z)))
+}
+
+// Neighbors6 returns the points sharing a face with p
+func (p Point3) Neighbors6() [6]Point3 {
+	return [6]Point3{
+		{p.X - 1, p.Y, p.Z}, {p.X + 1, p.Y, p.Z},
+		{p.X, p.Y - 1, p.Z}, {p.X, p.Y + 1, p.Z},
+		{p.X, p.Y, p.Z - 1}, {p.X, p.Y, p.Z + 1},
+	}
+}
+
+// Cuboid is the 3D counterpart of Rect, an axis aligned box of points from Min to Max inclusive
+type Cuboid struct {
+	Min, Max Point3
+}
+
+// BoundingCuboid returns the smallest Cuboid containing every point. The zero Cuboid is returned if there are no
+// points
+func BoundingCuboid(points ...Point3) Cuboid {
+	if len(points) == 0 {
+		return Cuboid{}
+	}
+	c := Cuboid{points[0], points[0]}
+	for _, p := range points[1:] {
+		c = c.Extend(p)
+	}
+	return c
+}
+
+// Extend returns the smallest Cuboid containing both c and p
+func (c Cuboid) Extend(p Point3) Cuboid {
+	return Cuboid{
+		Min: Point3{minInt(c.Min.X, p.X), minInt(c.Min.Y, p.Y), minInt(c.Min.Z, p.Z)},
+		Max: Point3{maxInt(c.Max.X, p.X), maxInt(c.Max.Y, p.Y), maxInt(c.Max.Z, p.Z)},
+	}
+}
+
+// Grow returns c with n more points on every side
+func (c Cuboid) Grow(n int) Cuboid {
+	return Cuboid{c.Min.Sub(Point3{n, n, n}), c.Max.Add(Point3{n, n, n})}
+}
+
+func (c Cuboid) Contains(p Point3) bool {
+	return p.X >= c.Min.X && p.X <= c.Max.X && p.Y >= c.Min.Y && p.Y <= c.Max.Y && p.Z >= c.Min.Z && p.Z <= c.Max.Z
+}
+
+// Grid is a dense, row major 2D grid of cells. The origin is the top left corner, with X increasing to the right and
+// Y increasing downward
+type Grid[T any] struct {
+	Width, Height int
+	Cells         []T
+}
+
+func NewGrid[T any](width, height int) *Grid[T] {
+	return &Grid[T]{Width: width, Height: height, Cells: make([]T, width*height)}
+}
+
+// In reports whether p lies within the bounds of the grid
+func (g *Grid[T]) In(p Point) bool {
+	return p.X >= 0 && p.X < g.Width && p.Y >= 0 && p.Y < g.Height
+}
+
+// At returns the cell at p. It panics if p is out of bounds, see [Grid.Get] for a safe alternative
+func (g *Grid[T]) At(p Point) T {
+	return g.Cells[g.index(p)]
+}
+
+// Get returns the cell at p, and false if p is out of bounds
+func (g *Grid[T]) Get(p Point) (T, bool) {
+	if !g.In(p) {
+		var zero T
+		return zero, false
+	}
+	return g.Cells[g.index(p)], true
+}
+
+func (g *Grid[T]) Set(p Point, v T) {
+	g.Cells[g.index(p)] = v
+}
+
+func (g *Grid[T]) index(p Point) int {
+	if !g.In(p) {
+		panic(fmt.Sprintf("point %v out of bounds of %vx%v grid", p, g.Width, g.Height))
+	}
+	return p.Y*g.Width + p.X
+}
+
+// Neighbors returns the in bounds orthogonal neighbors of p, in the order up, right, down, left
+func (g *Grid[T]) Neighbors(p Point) []Point {
+	neighbors := make([]Point, 0, 4)
+	for _, n := range p.Neighbors4() {
+		if g.In(n) {
+			neighbors = append(neighbors, n)
+		}
+	}
+	return neighbors
+}
+
+// Row returns row y of the grid. The returned slice shares memory with the grid, so writes to it are writes to the grid
+func (g *Grid[T]) Row(y int) []T {
+	return g.Cells[y*g.Width : (y+1)*g.Width : (y+1)*g.Width]
+}
+
+// Column returns a copy of column x of the grid
+func (g *Grid[T]) Column(x int) []T {
+	column := make([]T, g.Height)
+	for y := range column {
+		column[y] = g.Cells[y*g.Width+x]
+	}
+	return column
+}
+
+// SyntaxError reports malformed input along with the 1 indexed line and column it was found at
+type SyntaxError struct {
+	Line, Column int
+	Err          error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %v, column %v: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// ParseGridFunc parses one row of the grid per line, converting each rune to a cell with parse. Every row must be the
+// same width and there must be at least one row. Errors are reported as a [*SyntaxError]
+func ParseGridFunc[T any](r io.Reader, parse func(rune) (T, error)) (*Grid[T], error) {
+	return ParseGridFuncContext(context.Background(), r, parse)
+}
+
+func ParseGridFuncContext[T any](ctx context.Context, r io.Reader, parse func(rune) (T, error)) (*Grid[T], error) {
 	scanner := bufio.NewScanner(r)
-	grid := make(map[Point]int)
-	var y int
+	grid := new(Grid[T])
 	for scanner.Scan() {
-		for x, tree := range scanner.Text() {
-			grid[Point{X: x, Y: y}] = int(tree - '0')
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		line := grid.Height + 1
+		var width int
+		for _, c := range scanner.Text() {
+			if grid.Height > 0 && width == grid.Width {
+				return nil, &SyntaxError{line, width + 1, fmt.Errorf("row is longer than the first row's width of %v", grid.Width)}
+			}
+			cell, err := parse(c)
+			if err != nil {
+				return nil, &SyntaxError{line, width + 1, err}
+			}
+			grid.Cells = append(grid.Cells, cell)
+			width++
+		}
+		if grid.Height == 0 {
+			if width == 0 {
+				return nil, &SyntaxError{line, 1, errors.New("empty row")}
+			}
+			grid.Width = width
+		} else if width != grid.Width {
+			return nil, &SyntaxError{line, width + 1, fmt.Errorf("row is shorter than the first row's width of %v", grid.Width)}
 		}
-		y++
+		grid.Height++
 	}
 	if err := scanner.Err(); err != nil {
 		return nil, err
 	}
+	if grid.Height == 0 {
+		return nil, errors.New("empty grid")
+	}
 	return grid, nil
 }
 
-func CountVisibleAndScore(grid map[Point]int) (int, int) {
-	var visible int
-	var largestScore int
+// HeightAlphabet lists the runes that may appear in a height map, lowest first. The height of a rune is its index
+type HeightAlphabet string
 
-	for tree, height := range grid {
-		leftHidden := false
-		rightHidden := false
-		upHidden := false
-		downHidden := false
-		leftScore := 0
-		rightScore := 0
-		upScore := 0
-		downScore := 0
-
-		// check left
-		cur := Point{tree.X - 1, tree.Y}
-		for curHeight, ok := grid[cur]; ok; curHeight, ok = grid[cur] {
-			leftScore++
-			if curHeight >= height {
-				leftHidden = true
-				break
-			}
-			cur.X--
+const (
+	DigitHeights        HeightAlphabet = "0123456789"
+	AlphanumericHeights HeightAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+)
+
+func (a HeightAlphabet) Height(r rune) (int, error) {
+	var height int
+	for _, c := range a {
+		if c == r {
+			return height, nil
 		}
-		// check up
-		cur = Point{tree.X, tree.Y - 1}
-		for curHeight, ok := grid[cur]; ok; curHeight, ok = grid[cur] {
-			upScore++
-			if curHeight >= height {
-				upHidden = true
-				break
-			}
-			cur.Y--
+		height++
+	}
+	return 0, fmt.Errorf("invalid height %q", r)
+}
+
+// ParseGrid parses a grid of single digit heights
+func ParseGrid(r io.Reader) (*Grid[int], error) {
+	return ParseGridAlphabet(r, DigitHeights)
+}
+
+func ParseGridAlphabet(r io.Reader, alphabet HeightAlphabet) (*Grid[int], error) {
+	return ParseGridFunc(r, alphabet.Height)
+}
+
+func ParseGridContext(ctx context.Context, r io.Reader) (*Grid[int], error) {
+	return ParseGridAlphabetContext(ctx, r, DigitHeights)
+}
+
+func ParseGridAlphabetContext(ctx context.Context, r io.Reader, alphabet HeightAlphabet) (*Grid[int], error) {
+	return ParseGridFuncContext(ctx, r, alphabet.Height)
+}
+
+// TreeView describes what can be seen from a single tree. Up, Right, Down and Left are the viewing distances in each
+// direction, and Score is their product
+type TreeView struct {
+	Visible               bool
+	Up, Right, Down, Left int
+	Score                 int
+}
+
+// TreeViews computes the [TreeView] of every tree in the grid in O(Width*Height). Each row and column is swept once in
+// each direction with a monotonic stack of the trees that could still block the view of a later tree
+func TreeViews(grid *Grid[int]) *Grid[TreeView] {
+	views, _ := TreeViewsContext(context.Background(), grid)
+	return views
+}
+
+// TreeViewsContext checks for cancellation before sweeping each row and column
+func TreeViewsContext(ctx context.Context, grid *Grid[int]) (*Grid[TreeView], error) {
+	views := NewGrid[TreeView](grid.Width, grid.Height)
+	stack := make([]int, 0, grid.Width+grid.Height)
+	for y := 0; y < grid.Height; y++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		row := func(i int) Point { return Point{i, y} }
+		// sweeping right looks back to the left, and vice versa
+		sweep(grid, views, stack, grid.Width, row, false, func(v *TreeView) *int { return &v.Left })
+		sweep(grid, views, stack, grid.Width, row, true, func(v *TreeView) *int { return &v.Right })
+	}
+	for x := 0; x < grid.Width; x++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
+		column := func(i int) Point { return Point{x, i} }
+		sweep(grid, views, stack, grid.Height, column, false, func(v *TreeView) *int { return &v.Up })
+		sweep(grid, views, stack, grid.Height, column, true, func(v *TreeView) *int { return &v.Down })
+	}
+	for i := range views.Cells {
+		v := &views.Cells[i]
+		v.Score = v.Up * v.Right * v.Down * v.Left
+	}
+	return views, nil
+}
 
-		// check right
-		cur = Point{tree.X + 1, tree.Y}
-		for curHeight, ok := grid[cur]; ok; curHeight, ok = grid[cur] {
-			rightScore++
-			if curHeight >= height {
-				rightHidden = true
-				break
-			}
-			cur.X++
+// sweep walks a single line of n trees, located by at, recording for each tree the distance back to the nearest tree at
+// least as tall, or to the edge if there is none, in which case the tree is visible from that edge
+func sweep(grid *Grid[int], views *Grid[TreeView], stack []int, n int, at func(int) Point, reverse bool, distance func(*TreeView) *int) {
+	stack = stack[:0]
+	for step := 0; step < n; step++ {
+		i := step
+		if reverse {
+			i = n - 1 - step
 		}
-		// check down
-		cur = Point{tree.X, tree.Y + 1}
-		for curHeight, ok := grid[cur]; ok; curHeight, ok = grid[cur] {
-			downScore++
-			if curHeight >= height {
-				downHidden = true
-				break
+		height := grid.At(at(i))
+		for len(stack) > 0 && grid.At(at(stack[len(stack)-1])) < height {
+			stack = stack[:len(stack)-1]
+		}
+		view := &views.Cells[views.index(at(i))]
+		if len(stack) == 0 {
+			view.Visible = true
+			*distance(view) = step
+		} else {
+			blocker := stack[len(stack)-1]
+			if reverse {
+				*distance(view) = blocker - i
+			} else {
+				*distance(view) = i - blocker
 			}
-			cur.Y++
 		}
+		stack = append(stack, i)
+	}
+}
+
+func CountVisibleAndScore(grid *Grid[int]) (int, int) {
+	visible, largestScore, _ := CountVisibleAndScoreContext(context.Background(), grid)
+	return visible, largestScore
+}
 
-		if !upHidden || !downHidden || !leftHidden || !rightHidden {
+func CountVisibleAndScoreContext(ctx context.Context, grid *Grid[int]) (int, int, error) {
+	views, err := TreeViewsContext(ctx, grid)
+	if err != nil {
+		return 0, 0, err
+	}
+	var visible int
+	var largestScore int
+	for _, view := range views.Cells {
+		if view.Visible {
 			visible++
 		}
-		score := leftScore * rightScore * upScore * downScore
-		if score > largestScore {
-			largestScore = score
+		if view.Score > largestScore {
+			largestScore = view.Score
 		}
 	}
+	return visible, largestScore, nil
+}
 
-	return visible, largestScore
+// VisibleTrees returns the location of every tree visible from outside the grid
+func VisibleTrees(grid *Grid[int]) Set[Point] {
+	visible := NewSet[Point]()
+	views := TreeViews(grid)
+	for y := 0; y < views.Height; y++ {
+		for x, view := range views.Row(y) {
+			if view.Visible {
+				visible.Put(Point{x, y})
+			}
+		}
+	}
+	return visible
+}
+
+type ScenicTree struct {
+	Point Point
+	Score int
+}
+
+// TopScenicTrees returns the k trees with the highest scenic score, best first. Ties are broken by reading order. It
+// returns nil if k is not positive
+func TopScenicTrees(grid *Grid[int], k int) []ScenicTree {
+	if k <= 0 {
+		return nil
+	}
+	views := TreeViews(grid)
+	trees := make([]ScenicTree, 0, len(views.Cells))
+	for y := 0; y < views.Height; y++ {
+		for x, view := range views.Row(y) {
+			trees = append(trees, ScenicTree{Point: Point{x, y}, Score: view.Score})
+		}
+	}
+	sort.SliceStable(trees, func(i, j int) bool {
+		return trees[i].Score > trees[j].Score
+	})
+	if k < len(trees) {
+		trees = trees[:k]
+	}
+	return trees
+}
+
+// LineOfSight looks out from the tree at from in direction d, and returns the viewing distance in that direction along
+// with whether the tree can see all the way to the edge of the grid. There is no tree off the grid, so from there it
+// returns 0 and false
+func LineOfSight(grid *Grid[int], from Point, d Direction) (int, bool) {
+	height, ok := grid.Get(from)
+	if !ok {
+		return 0, false
+	}
+	distance := 0
+	for cur := from.Add(d.Delta()); grid.In(cur); cur = cur.Add(d.Delta()) {
+		distance++
+		if grid.At(cur) >= height {
+			return distance, false
+		}
+	}
+	return distance, true
+}
+
+// Motion is a single line of the rope bridge puzzle, moving the head of the rope Steps times in Direction
+type Motion struct {
+	Direction Direction
+	Steps     int
+}
+
+func ParseMotions(r io.Reader) ([]Motion, error) {
+	scanner := bufio.NewScanner(r)
+	var motions []Motion
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("expected a direction and steps on line %v %q", line, scanner.Text())
+		}
+		var motion Motion
+		steps, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("bad steps on line %v: %v", line, err)
+		}
+		motion.Steps = steps
+		switch direction := fields[0]; direction {
+		case "U":
+			motion.Direction = Up
+		case "R":
+			motion.Direction = Right
+		case "D":
+			motion.Direction = Down
+		case "L":
+			motion.Direction = Left
+		default:
+			return nil, fmt.Errorf("unknown direction %q on line %v", direction, line)
+		}
+		if motion.Steps < 0 {
+			return nil, fmt.Errorf("negative steps %v on line %v", motion.Steps, line)
+		}
+		motions = append(motions, motion)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return motions, nil
+}
+
+// Rope is a chain of knots, where Knots[0] is the head. Every knot starts at the origin
+type Rope struct {
+	Knots []Point
+}
+
+func NewRope(knots int) *Rope {
+	return &Rope{Knots: make([]Point, knots)}
+}
+
+// Step moves the head one step in direction d, and then each following knot toward the knot ahead of it
+func (r *Rope) Step(d Direction) {
+	r.Knots[0] = r.Knots[0].Add(d.Delta())
+	for i := 1; i < len(r.Knots); i++ {
+		ahead, knot := r.Knots[i-1], r.Knots[i]
+		if knot.Chebyshev(ahead) <= 1 {
+			// every later knot is already touching too
+			return
+		}
+		delta := ahead.Sub(knot)
+		r.Knots[i] = knot.Add(Point{sign(delta.X), sign(delta.Y)})
+	}
+}
+
+func sign(x int) int {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// SimulateRope moves a rope of the given number of knots through every motion, and returns the set of positions
+// visited by each knot, indexed the same as [Rope.Knots]
+func SimulateRope(motions []Motion, knots int) []Set[Point] {
+	if knots < 1 {
+		return nil
+	}
+	rope := NewRope(knots)
+	visited := make([]Set[Point], knots)
+	for i := range visited {
+		visited[i] = NewSet(Point{})
+	}
+	for _, motion := range motions {
+		for step := 0; step < motion.Steps; step++ {
+			rope.Step(motion.Direction)
+			for i, knot := range rope.Knots {
+				visited[i].Put(knot)
+			}
+		}
+	}
+	return visited
+}
+
+// TailVisits returns how many positions the last knot of a rope of the given length visits
+func TailVisits(motions []Motion, knots int) int {
+	visited := SimulateRope(motions, knots)
+	if len(visited) == 0 {
+		return 0
+	}
+	return visited[len(visited)-1].Len()
+}
+
+type Op int
+
+const (
+	Noop Op = iota
+	Addx
+)
+
+// Instruction is a single line of a CPU program. Arg is only used by Addx
+type Instruction struct {
+	Op  Op
+	Arg int
+}
+
+// Cycles returns how many cycles the instruction takes to complete
+func (i Instruction) Cycles() int {
+	if i.Op == Addx {
+		return 2
+	}
+	return 1
+}
+
+func ParseProgram(r io.Reader) ([]Instruction, error) {
+	scanner := bufio.NewScanner(r)
+	var program []Instruction
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 1 && fields[0] == "noop":
+			program = append(program, Instruction{Op: Noop})
+		case len(fields) == 2 && fields[0] == "addx":
+			arg, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("bad addx argument on line %v: %v", line, err)
+			}
+			program = append(program, Instruction{Op: Addx, Arg: arg})
+		default:
+			return nil, fmt.Errorf("unknown instruction on line %v %q", line, scanner.Text())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return program, nil
+}
+
+// RegisterTrace is the value of the X register during every cycle of a program, followed by its value once the program
+// has finished
+type RegisterTrace []int
+
+// Execute runs program, tracing the X register. X starts at 1
+func Execute(program []Instruction) RegisterTrace {
+	x := 1
+	var trace RegisterTrace
+	for _, instruction := range program {
+		for i := 0; i < instruction.Cycles(); i++ {
+			trace = append(trace, x)
+		}
+		if instruction.Op == Addx {
+			x += instruction.Arg
+		}
+	}
+	return append(trace, x)
+}
+
+// During returns the value of X during the 1 indexed cycle. Cycles after the program has finished see its final value.
+// It panics if cycle is less than 1, the first cycle
+func (t RegisterTrace) During(cycle int) int {
+	if cycle < 1 {
+		panic(fmt.Sprintf("cycle %v is before the first cycle", cycle))
+	}
+	if cycle > len(t) {
+		return t[len(t)-1]
+	}
+	return t[cycle-1]
+}
+
+// Cycles returns how many cycles the program took
+func (t RegisterTrace) Cycles() int {
+	return len(t) - 1
+}
+
+// DefaultSignalCycles are the cycles sampled for the puzzle answer
+var DefaultSignalCycles = []int{20, 60, 100, 140, 180, 220}
+
+// SumSignalStrength samples the signal strength, the cycle number times X, during each cycle and sums them. Every
+// cycle must be at least 1
+func SumSignalStrength(trace RegisterTrace, cycles []int) (int, error) {
+	var sum int
+	for _, cycle := range cycles {
+		if cycle < 1 {
+			return 0, fmt.Errorf("cycle %v is before the first cycle", cycle)
+		}
+		sum += cycle * trace.During(cycle)
+	}
+	return sum, nil
+}
+
+const (
+	CRTWidth  = 40
+	CRTHeight = 6
+)
+
+// RenderCRT draws the image produced by the CRT, one row per line with # for lit pixels and . for dark ones. The CRT
+// draws one pixel per cycle, which is lit if the 3 pixel wide sprite centered on X overlaps it
+func RenderCRT(trace RegisterTrace) string {
+	var sb strings.Builder
+	for y := 0; y < CRTHeight; y++ {
+		for x := 0; x < CRTWidth; x++ {
+			sprite := trace.During(y*CRTWidth + x + 1)
+			if abs(sprite-x) <= 1 {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// letters is the 4x6 font drawn by the CRT, each letter being followed by a blank column
+var letters = map[string]rune{
+	".##.#..##..######..##..#": 'A',
+	"###.#..####.#..##..####.": 'B',
+	".##.#..##...#...#..#.##.": 'C',
+	"#####...###.#...#...####": 'E',
+	"#####...###.#...#...#...": 'F',
+	".##.#..##...#.###..#.###": 'G',
+	"#..##..######..##..##..#": 'H',
+	".###..#...#...#...#..###": 'I',
+	"..##...#...#...##..#.##.": 'J',
+	"#..##.#.##..#.#.#.#.#..#": 'K',
+	"#...#...#...#...#...####": 'L',
+	".##.#..##..##..##..#.##.": 'O',
+	"###.#..##..####.#...#...": 'P',
+	"###.#..##..####.#.#.#..#": 'R',
+	".####...#....##....####.": 'S',
+	"#..##..##..##..##..#.##.": 'U',
+	"####...#..#..#..#...####": 'Z',
+}
+
+// ReadCRT decodes the letters in an image drawn by [RenderCRT]
+func ReadCRT(image string) (string, error) {
+	rows := strings.Split(strings.TrimRight(image, "\n"), "\n")
+	if len(rows) != CRTHeight {
+		return "", fmt.Errorf("image has %v rows, should have %v", len(rows), CRTHeight)
+	}
+	for i, row := range rows {
+		if len(row) != CRTWidth {
+			return "", fmt.Errorf("row %v has width %v, should have %v", i, len(row), CRTWidth)
+		}
+	}
+	var text []rune
+	for left := 0; left < CRTWidth; left += 5 {
+		var glyph strings.Builder
+		for _, row := range rows {
+			glyph.WriteString(row[left : left+4])
+		}
+		letter, ok := letters[glyph.String()]
+		if !ok {
+			return "", fmt.Errorf("unrecognized letter %v at column %v", len(text)+1, left)
+		}
+		text = append(text, letter)
+	}
+	return string(text), nil
+}
+
+// Operand is one side of a monkey's operation, either the old worry level or a constant
+type Operand struct {
+	Old   bool
+	Value int
+}
+
+func (o Operand) eval(old int) int {
+	if o.Old {
+		return old
+	}
+	return o.Value
+}
+
+func (o Operand) String() string {
+	if o.Old {
+		return "old"
+	}
+	return strconv.Itoa(o.Value)
+}
+
+// Operation is how a monkey changes the worry level of an item, such as `new = old * 19`
+type Operation struct {
+	Left, Right Operand
+	Operator    byte
+}
+
+func ParseOperation(s string) (Operation, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 || fields[0] != "new" || fields[1] != "=" || len(fields[3]) != 1 {
+		return Operation{}, fmt.Errorf("malformed operation %q", s)
+	}
+	var op Operation
+	op.Operator = fields[3][0]
+	if op.Operator != '+' && op.Operator != '*' {
+		return Operation{}, fmt.Errorf("unknown operator %q in %q", op.Operator, s)
+	}
+	for i, operand := range []*Operand{&op.Left, &op.Right} {
+		text := fields[2+2*i]
+		if text == "old" {
+			operand.Old = true
+			continue
+		}
+		value, err := strconv.Atoi(text)
+		if err != nil || value < 0 {
+			return Operation{}, fmt.Errorf("bad operand %q in %q", text, s)
+		}
+		operand.Value = value
+	}
+	return op, nil
+}
+
+func (o Operation) String() string {
+	return fmt.Sprintf("new = %v %c %v", o.Left, o.Operator, o.Right)
+}
+
+// ErrWorryOverflow is returned when a worry level no longer fits in an int
+var ErrWorryOverflow = errors.New("worry level overflowed")
+
+// Apply returns the new worry level. Worry levels are never negative, so any result that would not fit in an int is
+// reported as [ErrWorryOverflow] rather than wrapping
+func (o Operation) Apply(old int) (int, error) {
+	left, right := uint64(o.Left.eval(old)), uint64(o.Right.eval(old))
+	var result uint64
+	switch o.Operator {
+	case '+':
+		var carry uint64
+		result, carry = bits.Add64(left, right, 0)
+		if carry != 0 {
+			return 0, ErrWorryOverflow
+		}
+	case '*':
+		var hi uint64
+		hi, result = bits.Mul64(left, right)
+		if hi != 0 {
+			return 0, ErrWorryOverflow
+		}
+	}
+	if result > math.MaxInt {
+		return 0, ErrWorryOverflow
+	}
+	return int(result), nil
+}
+
+type Monkey struct {
+	Number    int
+	Items     []int
+	Operation Operation
+	// Divisor is the number the monkey tests worry levels are divisible by
+	Divisor         int
+	IfTrue, IfFalse int
+	Inspections     int
+}
+
+// ParseMonkeys parses the blank line separated monkey specifications. Monkeys must be listed in order, and can only
+// throw to monkeys that exist
+func ParseMonkeys(r io.Reader) ([]*Monkey, error) {
+	scanner := bufio.NewScanner(r)
+	var monkeys []*Monkey
+	var block []string
+	line := 0
+	flush := func() error {
+		if len(block) == 0 {
+			return nil
+		}
+		monkey, err := parseMonkey(block)
+		if err != nil {
+			return fmt.Errorf("monkey ending on line %v: %w", line, err)
+		}
+		if monkey.Number != len(monkeys) {
+			return fmt.Errorf("monkey %v out of order on line %v", monkey.Number, line)
+		}
+		monkeys = append(monkeys, monkey)
+		block = nil
+		return nil
+	}
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		block = append(block, text)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if err := checkMonkeyTargets(monkeys); err != nil {
+		return nil, err
+	}
+	return monkeys, nil
+}
+
+// checkMonkeyTargets checks every monkey throws to another monkey in monkeys, by index
+func checkMonkeyTargets(monkeys []*Monkey) error {
+	for i, m := range monkeys {
+		for _, target := range []int{m.IfTrue, m.IfFalse} {
+			if target < 0 || target >= len(monkeys) || target == i {
+				return fmt.Errorf("monkey %v can't throw to monkey %v", m.Number, target)
+			}
+		}
+	}
+	return nil
+}
+
+func parseMonkey(block []string) (*Monkey, error) {
+	if len(block) != 6 {
+		return nil, fmt.Errorf("expected 6 lines, got %v", len(block))
+	}
+	monkey := new(Monkey)
+	if n, err := fmt.Sscanf(block[0], "Monkey %d:", &monkey.Number); n != 1 || err != nil {
+		return nil, fmt.Errorf("bad header %q", block[0])
+	}
+	if !strings.HasPrefix(block[1], "Starting items:") {
+		return nil, fmt.Errorf("bad starting items %q", block[1])
+	}
+	if items := strings.TrimSpace(strings.TrimPrefix(block[1], "Starting items:")); items != "" {
+		for _, item := range strings.Split(items, ",") {
+			worry, err := strconv.Atoi(strings.TrimSpace(item))
+			if err != nil || worry < 0 {
+				return nil, fmt.Errorf("bad starting item %q", item)
+			}
+			monkey.Items = append(monkey.Items, worry)
+		}
+	}
+	if !strings.HasPrefix(block[2], "Operation:") {
+		return nil, fmt.Errorf("bad operation %q", block[2])
+	}
+	op, err := ParseOperation(strings.TrimPrefix(block[2], "Operation:"))
+	if err != nil {
+		return nil, err
+	}
+	monkey.Operation = op
+	if n, err := fmt.Sscanf(block[3], "Test: divisible by %d", &monkey.Divisor); n != 1 || err != nil || monkey.Divisor < 1 {
+		return nil, fmt.Errorf("bad test %q", block[3])
+	}
+	if n, err := fmt.Sscanf(block[4], "If true: throw to monkey %d", &monkey.IfTrue); n != 1 || err != nil {
+		return nil, fmt.Errorf("bad true target %q", block[4])
+	}
+	if n, err := fmt.Sscanf(block[5], "If false: throw to monkey %d", &monkey.IfFalse); n != 1 || err != nil {
+		return nil, fmt.Errorf("bad false target %q", block[5])
+	}
+	return monkey, nil
+}
+
+type ReliefMode int
+
+const (
+	// ReliefDivide divides worry levels by three after each inspection
+	ReliefDivide ReliefMode = iota + 1
+	// ReliefModulo keeps worry levels from growing without bound by reducing them modulo the product of every
+	// monkey's divisor, which leaves the result of every test unchanged
+	ReliefModulo
+	// ReliefNone lets worry levels grow until they overflow
+	ReliefNone
+)
+
+// SimulateMonkeys plays the given number of rounds, updating each monkey's items and inspection count
+func SimulateMonkeys(monkeys []*Monkey, rounds int, mode ReliefMode) error {
+	if err := checkMonkeyTargets(monkeys); err != nil {
+		return err
+	}
+	modulus := 1
+	for _, m := range monkeys {
+		if m.Divisor < 1 {
+			return fmt.Errorf("monkey %v tests divisibility by %v, which is not positive", m.Number, m.Divisor)
+		}
+		// the divisors are usually distinct primes, so the lcm is rarely smaller than the product
+		var ok bool
+		if modulus, ok = lcm(modulus, m.Divisor); !ok {
+			return fmt.Errorf("least common multiple of the divisors overflowed at monkey %v", m.Number)
+		}
+	}
+	for round := 0; round < rounds; round++ {
+		for _, m := range monkeys {
+			for _, item := range m.Items {
+				m.Inspections++
+				worry, err := m.Operation.Apply(item)
+				if err != nil {
+					return fmt.Errorf("monkey %v in round %v: %w", m.Number, round+1, err)
+				}
+				switch mode {
+				case ReliefDivide:
+					worry /= 3
+				case ReliefModulo:
+					worry %= modulus
+				}
+				target := m.IfFalse
+				if worry%m.Divisor == 0 {
+					target = m.IfTrue
+				}
+				monkeys[target].Items = append(monkeys[target].Items, worry)
+			}
+			m.Items = m.Items[:0]
+		}
+	}
+	return nil
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// lcm returns the least common multiple of positive a and b, and false if it doesn't fit in an int
+func lcm(a, b int) (int, bool) {
+	hi, lo := bits.Mul64(uint64(a/gcd(a, b)), uint64(b))
+	if hi != 0 || lo > math.MaxInt {
+		return 0, false
+	}
+	return int(lo), true
+}
+
+// MonkeyBusiness is the product of the two highest inspection counts, or 0 if there are fewer than two monkeys
+func MonkeyBusiness(monkeys []*Monkey) int {
+	if len(monkeys) < 2 {
+		return 0
+	}
+	top := TopK(monkeys, 2, func(a, b *Monkey) bool {
+		return a.Inspections < b.Inspections
+	})
+	business := 1
+	for _, m := range top {
+		business *= m.Inspections
+	}
+	return business
+}
+
+// HeightMap is the Day 12 elevation map, with heights from 0 for a to 25 for z
+type HeightMap struct {
+	Grid       *Grid[int]
+	Start, End Point
+}
+
+// ParseHeightMap parses a grid of a-z heights, with exactly one S marking the start at height a, and one E marking the
+// end at height z
+func ParseHeightMap(r io.Reader) (*HeightMap, error) {
+	runes, err := ParseGridFunc(r, func(c rune) (rune, error) {
+		if (c < 'a' || c > 'z') && c != 'S' && c != 'E' {
+			return 0, fmt.Errorf("invalid elevation %q", c)
+		}
+		return c, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	hm := &HeightMap{Grid: NewGrid[int](runes.Width, runes.Height)}
+	var starts, ends int
+	for y := 0; y < runes.Height; y++ {
+		for x, c := range runes.Row(y) {
+			p := Point{x, y}
+			switch c {
+			case 'S':
+				hm.Start = p
+				starts++
+				c = 'a'
+			case 'E':
+				hm.End = p
+				ends++
+				c = 'z'
+			}
+			hm.Grid.Set(p, int(c-'a'))
+		}
+	}
+	if starts != 1 || ends != 1 {
+		return nil, fmt.Errorf("expected exactly one S and one E, found %v and %v", starts, ends)
+	}
+	return hm, nil
+}
+
+// ShortestPath searches breadth first from every source at once for the fewest steps to End, climbing at most one
+// unit of height per step. It returns the path from the nearest source to End inclusive, and false if End is
+// unreachable
+func (hm *HeightMap) ShortestPath(sources []Point) ([]Point, bool) {
+	parents := NewGrid[Point](hm.Grid.Width, hm.Grid.Height)
+	seen := NewGrid[bool](hm.Grid.Width, hm.Grid.Height)
+	var queue Deque[Point]
+	for _, source := range sources {
+		if hm.Grid.In(source) && !seen.At(source) {
+			seen.Set(source, true)
+			parents.Set(source, source)
+			queue.PushBack(source)
+		}
+	}
+	for queue.Len() > 0 {
+		cur := queue.PopFront()
+		if cur == hm.End {
+			path := []Point{cur}
+			for parents.At(cur) != cur {
+				cur = parents.At(cur)
+				path = append(path, cur)
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path, true
+		}
+		for _, next := range hm.Grid.Neighbors(cur) {
+			if seen.At(next) || hm.Grid.At(next) > hm.Grid.At(cur)+1 {
+				continue
+			}
+			seen.Set(next, true)
+			parents.Set(next, cur)
+			queue.PushBack(next)
+		}
+	}
+	return nil, false
+}
+
+// Climb finds the shortest path from Start to End
+func (hm *HeightMap) Climb() ([]Point, bool) {
+	return hm.ShortestPath([]Point{hm.Start})
+}
+
+// Hike finds the shortest path to End from any square at the lowest elevation
+func (hm *HeightMap) Hike() ([]Point, bool) {
+	var sources []Point
+	for y := 0; y < hm.Grid.Height; y++ {
+		for x, height := range hm.Grid.Row(y) {
+			if height == 0 {
+				sources = append(sources, Point{x, y})
+			}
+		}
+	}
+	return hm.ShortestPath(sources)
+}
+
+// RenderPath draws path over the map the way the puzzle does, with an arrow on each square showing the direction of
+// the next step, E at the end, and . everywhere else
+func (hm *HeightMap) RenderPath(path []Point) string {
+	canvas := NewGrid[byte](hm.Grid.Width, hm.Grid.Height)
+	for i := range canvas.Cells {
+		canvas.Cells[i] = '.'
+	}
+	arrows := map[Point]byte{Up.Delta(): '^', Right.Delta(): '>', Down.Delta(): 'v', Left.Delta(): '<'}
+	for i := 0; i+1 < len(path); i++ {
+		canvas.Set(path[i], arrows[path[i+1].Sub(path[i])])
+	}
+	canvas.Set(hm.End, 'E')
+	var sb strings.Builder
+	for y := 0; y < canvas.Height; y++ {
+		sb.Write(canvas.Row(y))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Packet is a Day 13 packet value, either an integer or, when IsList is set, a list of packets
+type Packet struct {
+	Int    int
+	List   []Packet
+	IsList bool
+}
+
+// IntPacket returns an integer packet
+func IntPacket(n int) Packet {
+	return Packet{Int: n}
+}
+
+// ListPacket returns a list packet holding elems
+func ListPacket(elems ...Packet) Packet {
+	return Packet{List: elems, IsList: true}
+}
+
+// String formats p the way ParsePacket reads it, so the two round-trip
+func (p Packet) String() string {
+	var sb strings.Builder
+	p.write(&sb)
+	return sb.String()
+}
+
+func (p Packet) write(sb *strings.Builder) {
+	if !p.IsList {
+		sb.WriteString(strconv.Itoa(p.Int))
+		return
+	}
+	sb.WriteByte('[')
+	for i, elem := range p.List {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		elem.write(sb)
+	}
+	sb.WriteByte(']')
+}
+
+// ParsePacket parses a single packet such as [1,[2,[3]]]. Errors are reported as a [*SyntaxError] on line 1
+func ParsePacket(s string) (Packet, error) {
+	return parsePacket(s, 1)
+}
+
+func parsePacket(s string, line int) (Packet, error) {
+	fail := func(i int, format string, args ...any) (Packet, error) {
+		return Packet{}, &SyntaxError{line, i + 1, fmt.Errorf(format, args...)}
+	}
+	if !strings.HasPrefix(s, "[") {
+		return fail(0, "packet must start with [")
+	}
+	var open Stack[Packet]
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '[':
+			if open.Len() == 0 && i > 0 {
+				return fail(i, "unexpected %q after the end of the packet", c)
+			}
+			open.Push(ListPacket())
+		case c == ']':
+			if open.Len() == 0 {
+				return fail(i, "unmatched ]")
+			}
+			if s[i-1] == ',' {
+				return fail(i, "missing value after ,")
+			}
+			closed := open.Pop()
+			if open.Len() == 0 {
+				if i != len(s)-1 {
+					return fail(i+1, "unexpected %q after the end of the packet", s[i+1])
+				}
+				return closed, nil
+			}
+			parent := open.Pop()
+			parent.List = append(parent.List, closed)
+			open.Push(parent)
+		case c == ',':
+			if s[i-1] == '[' || s[i-1] == ',' {
+				return fail(i, "missing value before ,")
+			}
+		case c >= '0' && c <= '9':
+			if open.Len() == 0 {
+				return fail(i, "unexpected %q after the end of the packet", c)
+			}
+			end := i
+			for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+				end++
+			}
+			n, err := strconv.Atoi(s[i:end])
+			if err != nil {
+				return fail(i, "invalid integer %q", s[i:end])
+			}
+			parent := open.Pop()
+			parent.List = append(parent.List, IntPacket(n))
+			open.Push(parent)
+			i = end - 1
+		default:
+			return fail(i, "unexpected %q", c)
+		}
+		if i+1 < len(s) && s[i] != '[' && s[i] != ',' && s[i+1] != ']' && s[i+1] != ',' {
+			return fail(i+1, "expected , or ] but found %q", s[i+1])
+		}
+	}
+	return fail(len(s), "unterminated packet, missing %v ]", open.Len())
+}
+
+// ParsePackets parses one packet per non-blank line
+func ParsePackets(r io.Reader) ([]Packet, error) {
+	scanner := bufio.NewScanner(r)
+	var packets []Packet
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		packet, err := parsePacket(text, line)
+		if err != nil {
+			// Report columns in the line as written, not the line with its indentation removed
+			var syntaxErr *SyntaxError
+			if errors.As(err, &syntaxErr) {
+				syntaxErr.Column += len(scanner.Text()) - len(strings.TrimLeftFunc(scanner.Text(), unicode.IsSpace))
+			}
+			return nil, err
+		}
+		packets = append(packets, packet)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return packets, nil
+}
+
+// ComparePackets returns -1 if a is ordered before b, 1 if after, and 0 if neither. Integers compare by value, lists
+// compare element by element and then by length, and an integer compared with a list is first wrapped in a list
+func ComparePackets(a, b Packet) int {
+	switch {
+	case !a.IsList && !b.IsList:
+		return compareInts(a.Int, b.Int)
+	case !a.IsList:
+		return ComparePackets(ListPacket(a), b)
+	case !b.IsList:
+		return ComparePackets(a, ListPacket(b))
+	}
+	for i := 0; i < len(a.List) && i < len(b.List); i++ {
+		if c := ComparePackets(a.List[i], b.List[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(a.List), len(b.List))
+}
+
+// compareInts returns -1, 0 or 1 as a is less than, equal to or greater than b, without subtracting, which can
+// overflow
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// SumOrderedPairs sums the 1-based indices of the consecutive pairs of packets that are already in order
+func SumOrderedPairs(packets []Packet) (int, error) {
+	if len(packets)%2 != 0 {
+		return 0, fmt.Errorf("odd number of packets %v", len(packets))
+	}
+	sum := 0
+	for i := 0; i < len(packets); i += 2 {
+		if ComparePackets(packets[i], packets[i+1]) < 0 {
+			sum += i/2 + 1
+		}
+	}
+	return sum, nil
+}
+
+// DividerPackets are the packets [[2]] and [[6]] added before sorting in part 2
+var DividerPackets = []Packet{
+	ListPacket(ListPacket(IntPacket(2))),
+	ListPacket(ListPacket(IntPacket(6))),
+}
+
+// SortPackets sorts packets in place by ComparePackets
+func SortPackets(packets []Packet) {
+	sort.SliceStable(packets, func(i, j int) bool {
+		return ComparePackets(packets[i], packets[j]) < 0
+	})
+}
+
+// DecoderKey sorts packets together with the DividerPackets, and multiplies the 1-based indices of the dividers. The
+// dividers are found by position rather than by value, so a copy of a divider in packets doesn't change the key.
+// packets itself is not modified
+func DecoderKey(packets []Packet) int {
+	all := make([]Packet, 0, len(packets)+len(DividerPackets))
+	all = append(all, DividerPackets...)
+	all = append(all, packets...)
+	order := make([]int, len(all))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return ComparePackets(all[order[i]], all[order[j]]) < 0
+	})
+	key := 1
+	for i, index := range order {
+		if index < len(DividerPackets) {
+			key *= i + 1
+		}
+	}
+	return key
+}
+
+// Tile is the contents of one position in a Day 14 cave
+type Tile byte
+
+const (
+	TileAir Tile = iota
+	TileRock
+	TileSand
+)
+
+// SandSource is where every unit of sand enters the cave
+var SandSource = Point{500, 0}
+
+// SandRule decides what happens to sand that falls below the lowest rock
+type SandRule int
+
+const (
+	// SandAbyss lets sand fall forever once it passes the lowest rock
+	SandAbyss SandRule = iota
+	// SandFloor puts an infinitely wide floor two below the lowest rock
+	SandFloor
+)
+
+// Cave is a sparse map of the rock and resting sand in a Day 14 cave, with y increasing downwards. Positions missing
+// from Tiles are TileAir
+type Cave struct {
+	Tiles map[Point]Tile
+	// Bottom is the y of the lowest rock
+	Bottom int
+}
+
+// scanAll is like fmt.Sscanf, but fails unless format consumes all of s
+func scanAll(s, format string, args ...any) error {
+	r := strings.NewReader(s)
+	n, err := fmt.Fscanf(r, format, args...)
+	if err != nil {
+		return err
+	}
+	if n != len(args) {
+		return fmt.Errorf("scanned %v of %v values", n, len(args))
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("unexpected %q at the end", s[len(s)-r.Len():])
+	}
+	return nil
+}
+
+// ParseCave parses one path of rock per line, such as 498,4 -> 498,6 -> 496,6, where each segment is horizontal or
+// vertical and both ends are rock
+func ParseCave(r io.Reader) (*Cave, error) {
+	scanner := bufio.NewScanner(r)
+	cave := &Cave{Tiles: map[Point]Tile{}}
+	for line := 1; scanner.Scan(); line++ {
+		var path []Point
+		for _, field := range strings.Split(scanner.Text(), "->") {
+			var p Point
+			if err := scanAll(strings.TrimSpace(field), "%d,%d", &p.X, &p.Y); err != nil {
+				return nil, fmt.Errorf("failed to scan point %q on line %v: %v", field, line, err)
+			}
+			if p.Y < 0 {
+				return nil, fmt.Errorf("point %v above the sand source on line %v", p, line)
+			}
+			path = append(path, p)
+		}
+		for i, p := range path {
+			cave.Tiles[p] = TileRock
+			cave.Bottom = maxInt(cave.Bottom, p.Y)
+			if i == 0 {
+				continue
+			}
+			prev := path[i-1]
+			if prev.X != p.X && prev.Y != p.Y {
+				return nil, fmt.Errorf("diagonal segment from %v to %v on line %v", prev, p, line)
+			}
+			step := Point{sign(p.X - prev.X), sign(p.Y - prev.Y)}
+			for q := prev; q != p; q = q.Add(step) {
+				cave.Tiles[q] = TileRock
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(cave.Tiles) == 0 {
+		return nil, errors.New("no rock in cave")
+	}
+	return cave, nil
+}
+
+// At returns the tile at p, including the floor under rule
+func (c *Cave) At(p Point, rule SandRule) Tile {
+	if rule == SandFloor && p.Y == c.Bottom+2 {
+		return TileRock
+	}
+	return c.Tiles[p]
+}
+
+// Drop lets one unit of sand fall from SandSource, trying straight down, then down-left, then down-right, until it
+// comes to rest. It returns where the sand rested, or false if the source is blocked or the sand fell into the abyss
+func (c *Cave) Drop(rule SandRule) (Point, bool) {
+	if c.Tiles[SandSource] != TileAir {
+		return Point{}, false
+	}
+	sand := SandSource
+	for {
+		if rule == SandAbyss && sand.Y > c.Bottom {
+			return Point{}, false
+		}
+		moved := false
+		for _, dx := range []int{0, -1, 1} {
+			next := Point{sand.X + dx, sand.Y + 1}
+			if c.At(next, rule) == TileAir {
+				sand, moved = next, true
+				break
+			}
+		}
+		if !moved {
+			c.Tiles[sand] = TileSand
+			return sand, true
+		}
+	}
+}
+
+// Fill drops sand until it stops coming to rest, and returns how many units rested
+func (c *Cave) Fill(rule SandRule) int {
+	units := 0
+	for {
+		if _, ok := c.Drop(rule); !ok {
+			return units
+		}
+		units++
+	}
+}
+
+// Render draws the cave the way the puzzle does, with # for rock, o for sand, + for the source and . for air. The
+// picture covers every rock, sand and the source, plus the floor under rule
+func (c *Cave) Render(rule SandRule) string {
+	bounds := BoundingBox(SandSource)
+	for p := range c.Tiles {
+		bounds = bounds.Extend(p)
+	}
+	if rule == SandFloor {
+		bounds = bounds.Extend(Point{bounds.Min.X, c.Bottom + 2})
+	}
+	var sb strings.Builder
+	for y := bounds.Min.Y; y <= bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x <= bounds.Max.X; x++ {
+			p := Point{x, y}
+			switch {
+			case p == SandSource && c.Tiles[p] == TileAir:
+				sb.WriteByte('+')
+			case c.At(p, rule) == TileRock:
+				sb.WriteByte('#')
+			case c.At(p, rule) == TileSand:
+				sb.WriteByte('o')
+			default:
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+const (
+	// DistressRow is the row part 1 counts excluded positions on
+	DistressRow = 2_000_000
+	// DistressBound is the largest x and y the distress beacon can have in part 2
+	DistressBound = 4_000_000
+)
+
+// Sensor is a Day 15 sensor and the closest beacon it detected
+type Sensor struct {
+	Position, Beacon Point
+}
+
+// Radius returns the Manhattan distance to the sensor's beacon. No other beacon can be within it
+func (s Sensor) Radius() int {
+	return s.Position.Manhattan(s.Beacon)
+}
+
+// Covers reports whether p is within the sensor's radius
+func (s Sensor) Covers(p Point) bool {
+	return s.Position.Manhattan(p) <= s.Radius()
+}
+
+// Row returns the positions on row y that are within the sensor's radius, and false if there are none
+func (s Sensor) Row(y int) (Assignment, bool) {
+	reach := s.Radius() - abs(s.Position.Y-y)
+	if reach < 0 {
+		return Assignment{}, false
+	}
+	return Assignment{Start: s.Position.X - reach, End: s.Position.X + reach}, true
+}
+
+// ParseSensors parses one line per sensor, such as Sensor at x=2, y=18: closest beacon is at x=-2, y=15
+func ParseSensors(r io.Reader) ([]Sensor, error) {
+	scanner := bufio.NewScanner(r)
+	var sensors []Sensor
+	for line := 1; scanner.Scan(); line++ {
+		var s Sensor
+		err := scanAll(scanner.Text(), "Sensor at x=%d, y=%d: closest beacon is at x=%d, y=%d",
+			&s.Position.X, &s.Position.Y, &s.Beacon.X, &s.Beacon.Y)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sensor on line %v %q: %v", line, scanner.Text(), err)
+		}
+		sensors = append(sensors, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sensors, nil
+}
+
+// RowCoverage returns the merged positions on row y that are within the radius of any sensor
+func RowCoverage(sensors []Sensor, y int) []Assignment {
+	var intervals []Assignment
+	for _, s := range sensors {
+		if a, ok := s.Row(y); ok {
+			intervals = append(intervals, a)
+		}
+	}
+	return MergeAssignments(intervals)
+}
+
+// ExcludedPositions counts the positions on row y where a beacon cannot be, which are those covered by a sensor
+// except where a beacon already is
+func ExcludedPositions(sensors []Sensor, y int) int {
+	coverage := RowCoverage(sensors, y)
+	count := 0
+	for _, a := range coverage {
+		count += a.Len()
+	}
+	beacons := NewSet[Point]()
+	for _, s := range sensors {
+		if s.Beacon.Y == y {
+			beacons.Put(s.Beacon)
+		}
+	}
+	for beacon := range beacons {
+		for _, a := range coverage {
+			if beacon.X >= a.Start && beacon.X <= a.End {
+				count--
+				break
+			}
+		}
+	}
+	return count
+}
+
+// FindDistressBeacon finds a position with x and y between 0 and bound that no sensor covers. A single such position
+// must be next to the edge of some sensor's radius, so rather than scanning the square it only checks where the
+// diagonal lines just outside each sensor's radius cross each other and the edges of the square. It returns false if
+// every candidate is covered
+func FindDistressBeacon(sensors []Sensor, bound int) (Point, bool) {
+	// Ascending lines are y = x + c, and descending lines are y = -x + d
+	var ascending, descending []int
+	for _, s := range sensors {
+		r := s.Radius() + 1
+		ascending = append(ascending, s.Position.Y-s.Position.X-r, s.Position.Y-s.Position.X+r)
+		descending = append(descending, s.Position.Y+s.Position.X-r, s.Position.Y+s.Position.X+r)
+	}
+	candidates := []Point{{0, 0}, {0, bound}, {bound, 0}, {bound, bound}}
+	for _, c := range ascending {
+		for _, d := range descending {
+			if (d-c)%2 == 0 {
+				candidates = append(candidates, Point{(d - c) / 2, (c + d) / 2})
+			}
+		}
+		candidates = append(candidates, Point{0, c}, Point{bound, bound + c}, Point{-c, 0}, Point{bound - c, bound})
+	}
+	for _, d := range descending {
+		candidates = append(candidates, Point{0, d}, Point{bound, d - bound}, Point{d, 0}, Point{d - bound, bound})
+	}
+	square := Rect{Max: Point{bound, bound}}
+	for _, p := range candidates {
+		if !square.Contains(p) {
+			continue
+		}
+		covered := false
+		for _, s := range sensors {
+			if s.Covers(p) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return p, true
+		}
+	}
+	return Point{}, false
+}
+
+// TuningFrequency returns x*4000000 + y for the distress beacon at p
+func TuningFrequency(p Point) int {
+	return p.X*4_000_000 + p.Y
+}
+
+// Valve is a Day 16 valve, with the pressure it releases per minute once open, and the valves its tunnels lead to
+type Valve struct {
+	Name    string
+	Rate    int
+	Tunnels []string
+}
+
+// ParseValves parses one valve per line, such as Valve AA has flow rate=0; tunnels lead to valves DD, II, BB. Every
+// tunnel must lead to a valve in the input
+func ParseValves(r io.Reader) ([]Valve, error) {
+	scanner := bufio.NewScanner(r)
+	var valves []Valve
+	names := NewSet[string]()
+	for line := 1; scanner.Scan(); line++ {
+		valve, tunnels, ok := strings.Cut(scanner.Text(), "; ")
+		if !ok {
+			return nil, fmt.Errorf("missing tunnels on line %v %q", line, scanner.Text())
+		}
+		var v Valve
+		n, err := fmt.Sscanf(valve, "Valve %s has flow rate=%d", &v.Name, &v.Rate)
+		if n != 2 || err != nil {
+			return nil, fmt.Errorf("failed to scan valve on line %v %q: %v", line, scanner.Text(), err)
+		}
+		if v.Rate < 0 {
+			return nil, fmt.Errorf("negative flow rate %v on line %v", v.Rate, line)
+		}
+		if names.Contains(v.Name) {
+			return nil, fmt.Errorf("duplicate valve %v on line %v", v.Name, line)
+		}
+		names.Put(v.Name)
+		for _, prefix := range []string{"tunnels lead to valves ", "tunnel leads to valve "} {
+			if strings.HasPrefix(tunnels, prefix) {
+				v.Tunnels = strings.Split(strings.TrimPrefix(tunnels, prefix), ", ")
+				break
+			}
+		}
+		if v.Tunnels == nil {
+			return nil, fmt.Errorf("failed to scan tunnels on line %v %q", line, tunnels)
+		}
+		valves = append(valves, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, v := range valves {
+		for _, tunnel := range v.Tunnels {
+			if !names.Contains(tunnel) {
+				return nil, fmt.Errorf("valve %v has a tunnel to unknown valve %q", v.Name, tunnel)
+			}
+		}
+	}
+	return valves, nil
+}
+
+// ValveNetwork is the valve graph compressed to the valves worth opening, plus the start, with the minutes it takes
+// to walk between each pair of them
+type ValveNetwork struct {
+	Valves []Valve
+	// Dist[i][j] is the minutes to walk from Valves[i] to Valves[j], or -1 if there is no way there
+	Dist [][]int
+	// Start is the index of the valve every actor starts at
+	Start int
+}
+
+// CompressValves keeps the valves with a non-zero flow rate and the start valve, and finds the shortest walk between
+// every pair of them with a breadth first search from each. At most 64 valves can have a non-zero flow rate, so that
+// the open valves fit in a bitmask. A start valve with a zero flow rate comes after them, outside the bitmask
+func CompressValves(valves []Valve, start string) (*ValveNetwork, error) {
+	index := map[string]int{}
+	for i, v := range valves {
+		index[v.Name] = i
+	}
+	if _, ok := index[start]; !ok {
+		return nil, fmt.Errorf("no start valve %v", start)
+	}
+	network := &ValveNetwork{Start: -1}
+	for _, v := range valves {
+		if v.Rate > 0 {
+			if v.Name == start {
+				network.Start = len(network.Valves)
+			}
+			network.Valves = append(network.Valves, v)
+		}
+	}
+	if len(network.Valves) > 64 {
+		return nil, fmt.Errorf("%v valves to open is more than 64", len(network.Valves))
+	}
+	if network.Start < 0 {
+		network.Start = len(network.Valves)
+		network.Valves = append(network.Valves, valves[index[start]])
+	}
+	for _, from := range network.Valves {
+		dist := make([]int, len(valves))
+		for i := range dist {
+			dist[i] = -1
+		}
+		dist[index[from.Name]] = 0
+		var queue Deque[int]
+		queue.PushBack(index[from.Name])
+		for queue.Len() > 0 {
+			cur := queue.PopFront()
+			for _, tunnel := range valves[cur].Tunnels {
+				if next := index[tunnel]; dist[next] < 0 {
+					dist[next] = dist[cur] + 1
+					queue.PushBack(next)
+				}
+			}
+		}
+		row := make([]int, len(network.Valves))
+		for j, to := range network.Valves {
+			row[j] = dist[index[to.Name]]
+		}
+		network.Dist = append(network.Dist, row)
+	}
+	return network, nil
+}
+
+// ValveOpening is a valve opened by an actor, and the minute it was opened in
+type ValveOpening struct {
+	Valve  string
+	Minute int
+}
+
+// ValvePlan is the most pressure a group of actors can release, with the valves each of them opens in order
+type ValvePlan struct {
+	Pressure int
+	Orders   [][]ValveOpening
+}
+
+// valvePath is the most pressure one actor can release by opening exactly the valves in opened, and the order to open
+// them in
+type valvePath struct {
+	pressure int
+	opened   uint64
+	order    []ValveOpening
+}
+
+// paths walks every order one actor can open valves in within minutes, and keeps the best path for each set of open
+// valves, sorted by most pressure first
+func (n *ValveNetwork) paths(minutes int) []valvePath {
+	best := map[uint64]valvePath{}
+	var order []ValveOpening
+	var walk func(at, left int, opened uint64, pressure int)
+	walk = func(at, left int, opened uint64, pressure int) {
+		if p, ok := best[opened]; !ok || pressure > p.pressure {
+			best[opened] = valvePath{pressure, opened, append([]ValveOpening(nil), order...)}
+		}
+		for v, valve := range n.Valves {
+			dist := n.Dist[at][v]
+			next := left - dist - 1
+			if valve.Rate == 0 || opened&(1<<v) != 0 || dist < 0 || next <= 0 {
+				continue
+			}
+			order = append(order, ValveOpening{valve.Name, minutes - next})
+			walk(v, next, opened|1<<v, pressure+valve.Rate*next)
+			order = order[:len(order)-1]
+		}
+	}
+	walk(n.Start, minutes, 0, 0)
+	paths := make([]valvePath, 0, len(best))
+	for _, p := range best {
+		paths = append(paths, p)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if paths[i].pressure != paths[j].pressure {
+			return paths[i].pressure > paths[j].pressure
+		}
+		return paths[i].opened < paths[j].opened
+	})
+	return paths
+}
+
+type valveState struct {
+	actors  int
+	allowed uint64
+}
+
+type valveChoice struct {
+	pressure, path int
+}
+
+// Plan finds the most pressure actors working together can release in minutes, and reports the valves each actor
+// opens in order. Each actor takes one of the best single actor paths, so a memoized depth first search over the
+// actors left and the bitmask of valves they may still open finds the best combination of paths that don't share a
+// valve
+func (n *ValveNetwork) Plan(minutes, actors int) ValvePlan {
+	paths := n.paths(minutes)
+	memo := map[valveState]valveChoice{}
+	var search func(s valveState) valveChoice
+	search = func(s valveState) valveChoice {
+		if s.actors == 0 {
+			return valveChoice{0, -1}
+		}
+		if c, ok := memo[s]; ok {
+			return c
+		}
+		best := valveChoice{0, -1}
+		for i, p := range paths {
+			// Paths are sorted, so from here on no actor releases more than p, and neither can all of them together
+			if p.pressure*s.actors <= best.pressure && best.path >= 0 {
+				break
+			}
+			if p.opened&^s.allowed != 0 {
+				continue
+			}
+			rest := search(valveState{s.actors - 1, s.allowed &^ p.opened})
+			if pressure := p.pressure + rest.pressure; pressure > best.pressure || best.path < 0 {
+				best = valveChoice{pressure, i}
+			}
+		}
+		memo[s] = best
+		return best
+	}
+	s := valveState{actors, ^uint64(0)}
+	plan := ValvePlan{Pressure: search(s).pressure}
+	for ; s.actors > 0; s.actors-- {
+		c := search(s)
+		if c.path < 0 {
+			break
+		}
+		plan.Orders = append(plan.Orders, paths[c.path].order)
+		s.allowed &^= paths[c.path].opened
+	}
+	return plan
+}
+
+func parseValveNetwork(r io.Reader) (*ValveNetwork, error) {
+	valves, err := ParseValves(r)
+	if err != nil {
+		return nil, err
+	}
+	return CompressValves(valves, "AA")
+}
+
+// ChamberWidth is how many units wide the Day 17 chamber is
+const ChamberWidth = 7
+
+// rockShape is one of the falling rocks, with its rows from the bottom up. Bit x of a row is set where the rock fills
+// column x, with the rock pushed against the left wall
+type rockShape struct {
+	rows  []uint8
+	width int
+}
+
+// rockShapes fall in this order, over and over
+var rockShapes = [...]rockShape{
+	{[]uint8{0b1111}, 4},
+	{[]uint8{0b010, 0b111, 0b010}, 3},
+	{[]uint8{0b111, 0b100, 0b100}, 3},
+	{[]uint8{0b1, 0b1, 0b1, 0b1}, 1},
+	{[]uint8{0b11, 0b11}, 2},
+}
+
+// ParseJets parses the jet pattern, where < pushes rocks Left and > pushes them Right. Trailing whitespace is ignored
+func ParseJets(r io.Reader) ([]Direction, error) {
+	var jets []Direction
+	var err error
+	trailing := false
+	readErr := readSignal(context.Background(), r, 1, func(i int, window []byte) bool {
+		switch c := window[0]; {
+		case c == '<' && !trailing:
+			jets = append(jets, Left)
+		case c == '>' && !trailing:
+			jets = append(jets, Right)
+		case c == '\n' || c == '\r' || c == ' ' || c == '\t':
+			trailing = true
+		default:
+			err = &SyntaxError{1, i + 1, fmt.Errorf("invalid jet %q", c)}
+			return true
+		}
+		return false
+	})
+	if readErr != nil {
+		return nil, readErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(jets) == 0 {
+		return nil, errors.New("no jets")
+	}
+	return jets, nil
+}
+
+// Chamber is the Day 17 chamber that rocks fall into while jets push them from side to side
+type Chamber struct {
+	// Rows are the settled rocks from the floor up, with bit x set where column x is filled
+	Rows  []uint8
+	jets  []Direction
+	jet   int
+	rocks int
+}
+
+// NewChamber returns an empty chamber that pushes rocks with jets, repeating them forever. There must be at least one
+// jet, and every jet must be Left or Right
+func NewChamber(jets []Direction) (*Chamber, error) {
+	if len(jets) == 0 {
+		return nil, errors.New("no jets")
+	}
+	for i, jet := range jets {
+		if jet != Left && jet != Right {
+			return nil, fmt.Errorf("jet %v pushes %v, not left or right", i, jet)
+		}
+	}
+	return &Chamber{jets: jets}, nil
+}
+
+// Height returns the height of the tower of settled rocks
+func (c *Chamber) Height() int {
+	return len(c.Rows)
+}
+
+// Rocks returns how many rocks have settled
+func (c *Chamber) Rocks() int {
+	return c.rocks
+}
+
+func (c *Chamber) fits(shape rockShape, x, y int) bool {
+	if x < 0 || x+shape.width > ChamberWidth || y < 0 {
+		return false
+	}
+	for i, row := range shape.rows {
+		if y+i < len(c.Rows) && c.Rows[y+i]&(row<<x) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Drop lets the next rock fall from two units away from the left wall and three units above the tower, alternately
+// pushed by the next jet and falling one unit, until it can't fall any further
+func (c *Chamber) Drop() {
+	shape := rockShapes[c.rocks%len(rockShapes)]
+	x, y := 2, len(c.Rows)+3
+	for {
+		push := x + c.jets[c.jet].Delta().X
+		c.jet = (c.jet + 1) % len(c.jets)
+		if c.fits(shape, push, y) {
+			x = push
+		}
+		if !c.fits(shape, x, y-1) {
+			break
+		}
+		y--
+	}
+	for i, row := range shape.rows {
+		for y+i >= len(c.Rows) {
+			c.Rows = append(c.Rows, 0)
+		}
+		c.Rows[y+i] |= row << x
+	}
+	c.rocks++
+}
+
+// surface returns how far below the top of the tower the highest rock in each column is
+func (c *Chamber) surface() [ChamberWidth]int {
+	var depths [ChamberWidth]int
+	for x := range depths {
+		depths[x] = len(c.Rows)
+		for y := len(c.Rows) - 1; y >= 0; y-- {
+			if c.Rows[y]&(1<<x) != 0 {
+				depths[x] = len(c.Rows) - 1 - y
+				break
+			}
+		}
+	}
+	return depths
+}
+
+// String draws the chamber the way the puzzle does, from the top down, with # for rock and . for air
+func (c *Chamber) String() string {
+	var sb strings.Builder
+	for y := len(c.Rows) - 1; y >= 0; y-- {
+		sb.WriteByte('|')
+		for x := 0; x < ChamberWidth; x++ {
+			if c.Rows[y]&(1<<x) != 0 {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteString("|\n")
+	}
+	sb.WriteString("+" + strings.Repeat("-", ChamberWidth) + "+\n")
+	return sb.String()
+}
+
+type chamberState struct {
+	shape, jet int
+	surface    [ChamberWidth]int
+}
+
+// TowerHeight returns how tall the tower is after rocks have settled. Once the next shape, the next jet and the
+// surface of the tower repeat, the tower grows the same way forever, so whole cycles are skipped once, and the rocks
+// left over after the last whole cycle are simulated one by one
+func TowerHeight(jets []Direction, rocks int) (int, error) {
+	c, err := NewChamber(jets)
+	if err != nil {
+		return 0, err
+	}
+	type seen struct{ rocks, height int }
+	states := map[chamberState]seen{}
+	skipped := 0
+	for c.Rocks() < rocks {
+		if states != nil {
+			state := chamberState{c.Rocks() % len(rockShapes), c.jet, c.surface()}
+			if prev, ok := states[state]; ok {
+				cycle := c.Rocks() - prev.rocks
+				cycles := (rocks - c.Rocks()) / cycle
+				skipped = cycles * (c.Height() - prev.height)
+				c.rocks += cycles * cycle
+				states = nil
+				continue
+			}
+			states[state] = seen{c.Rocks(), c.Height()}
+		}
+		c.Drop()
+	}
+	return c.Height() + skipped, nil
+}
+
+// ParseCubes parses one 1x1x1 cube per line, such as 2,2,2, identified by its position
+func ParseCubes(r io.Reader) (Set[Point3], error) {
+	scanner := bufio.NewScanner(r)
+	cubes := NewSet[Point3]()
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Split(scanner.Text(), ",")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("expected x,y,z on line %v %q", line, scanner.Text())
+		}
+		var coords [3]int
+		for i, field := range fields {
+			n, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("bad coordinate on line %v: %v", line, err)
+			}
+			coords[i] = n
+		}
+		cubes.Put(Point3{coords[0], coords[1], coords[2]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cubes, nil
+}
+
+// SurfaceArea counts the faces of cubes that don't touch another cube, including those of air pockets inside
+func SurfaceArea(cubes Set[Point3]) int {
+	area := 0
+	for cube := range cubes {
+		for _, n := range cube.Neighbors6() {
+			if !cubes.Contains(n) {
+				area++
+			}
+		}
+	}
+	return area
+}
+
+// ExteriorSurfaceArea counts only the faces of cubes that steam could reach from outside. It flood fills the air in a
+// box one larger than cubes on every side, so the air wraps all the way around them, and counts each face the air
+// runs into
+func ExteriorSurfaceArea(cubes Set[Point3]) int {
+	if cubes.Len() == 0 {
+		return 0
+	}
+	box := BoundingCuboid(cubes.Slice()...).Grow(1)
+	air := NewSet(box.Min)
+	var queue Deque[Point3]
+	queue.PushBack(box.Min)
+	area := 0
+	for queue.Len() > 0 {
+		cur := queue.PopFront()
+		for _, n := range cur.Neighbors6() {
+			switch {
+			case cubes.Contains(n):
+				area++
+			case box.Contains(n) && !air.Contains(n):
+				air.Put(n)
+				queue.PushBack(n)
+			}
+		}
+	}
+	return area
+}
+
+// Solution solves one part of a day's puzzle, formatting the answer as it would be submitted
+type Solution func(r io.Reader) (string, error)
+
+type Puzzle struct {
+	Day          int
+	Part1, Part2 Solution
+}
+
+// Part returns the solution to part 1 or 2
+func (p Puzzle) Part(part int) Solution {
+	if part == 1 {
+		return p.Part1
+	}
+	return p.Part2
+}
+
+// Puzzles is every solved day, in order
+var Puzzles = []Puzzle{
+	{
+		Day: 1,
+		Part1: func(r io.Reader) (string, error) {
+			elves, err := GetElves(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(GetLargestElf(elves).Calories), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			elves, err := GetElves(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(SumThreeLargestElves(elves)), nil
+		},
+	},
+	{
+		Day: 2,
+		Part1: func(r io.Reader) (string, error) {
+			guide, err := ParseStrategyGuide(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(CalculateRPSScore(guide, ModeSelf)), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			guide, err := ParseStrategyGuide(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(CalculateRPSScore(guide, ModeOutcome)), nil
+		},
+	},
+	{
+		Day: 3,
+		Part1: func(r io.Reader) (string, error) {
+			sum, err := SumPriority(r)
+			return strconv.Itoa(sum), err
+		},
+		Part2: func(r io.Reader) (string, error) {
+			sum, err := SumBadgePriority(r)
+			return strconv.Itoa(sum), err
+		},
+	},
+	{
+		Day: 4,
+		Part1: func(r io.Reader) (string, error) {
+			assignments, err := ParseAssignments(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(SumFullyOverlaps(assignments)), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			assignments, err := ParseAssignments(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(SumOverlappingSections(assignments)), nil
+		},
+	},
+	{
+		Day: 5,
+		Part1: func(r io.Reader) (string, error) {
+			stacks, steps, err := ParseStacksAndSteps(r)
+			if err != nil {
+				return "", err
+			}
+			return SumTopOfStacks(ProcessSteps(stacks, steps)), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			stacks, steps, err := ParseStacksAndSteps(r)
+			if err != nil {
+				return "", err
+			}
+			return SumTopOfStacks(ProcessSteps9001(stacks, steps)), nil
+		},
+	},
+	{
+		Day: 6,
+		Part1: func(r io.Reader) (string, error) {
+			return strconv.Itoa(StartOfPacket(r)), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			return strconv.Itoa(StartOfMessage(r)), nil
+		},
+	},
+	{
+		Day: 7,
+		Part1: func(r io.Reader) (string, error) {
+			root, err := ParseFS(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(SumDirSize(root.GetDirs())), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			root, err := ParseFS(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(SmallestDirToDelete(root)), nil
+		},
+	},
+	{
+		Day: 8,
+		Part1: func(r io.Reader) (string, error) {
+			grid, err := ParseGrid(r)
+			if err != nil {
+				return "", err
+			}
+			visible, _ := CountVisibleAndScore(grid)
+			return strconv.Itoa(visible), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			grid, err := ParseGrid(r)
+			if err != nil {
+				return "", err
+			}
+			_, score := CountVisibleAndScore(grid)
+			return strconv.Itoa(score), nil
+		},
+	},
+	{
+		Day: 9,
+		Part1: func(r io.Reader) (string, error) {
+			motions, err := ParseMotions(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(TailVisits(motions, 2)), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			motions, err := ParseMotions(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(TailVisits(motions, 10)), nil
+		},
+	},
+	{
+		Day: 10,
+		Part1: func(r io.Reader) (string, error) {
+			program, err := ParseProgram(r)
+			if err != nil {
+				return "", err
+			}
+			strength, err := SumSignalStrength(Execute(program), DefaultSignalCycles)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(strength), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			program, err := ParseProgram(r)
+			if err != nil {
+				return "", err
+			}
+			return ReadCRT(RenderCRT(Execute(program)))
+		},
+	},
+	{
+		Day: 11,
+		Part1: func(r io.Reader) (string, error) {
+			monkeys, err := ParseMonkeys(r)
+			if err != nil {
+				return "", err
+			}
+			if err := SimulateMonkeys(monkeys, 20, ReliefDivide); err != nil {
+				return "", err
+			}
+			return strconv.Itoa(MonkeyBusiness(monkeys)), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			monkeys, err := ParseMonkeys(r)
+			if err != nil {
+				return "", err
+			}
+			if err := SimulateMonkeys(monkeys, 10_000, ReliefModulo); err != nil {
+				return "", err
+			}
+			return strconv.Itoa(MonkeyBusiness(monkeys)), nil
+		},
+	},
+	{
+		Day: 12,
+		Part1: func(r io.Reader) (string, error) {
+			hm, err := ParseHeightMap(r)
+			if err != nil {
+				return "", err
+			}
+			path, ok := hm.Climb()
+			if !ok {
+				return "", errors.New("no path from S to E")
+			}
+			return strconv.Itoa(len(path) - 1), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			hm, err := ParseHeightMap(r)
+			if err != nil {
+				return "", err
+			}
+			path, ok := hm.Hike()
+			if !ok {
+				return "", errors.New("no path from any a to E")
+			}
+			return strconv.Itoa(len(path) - 1), nil
+		},
+	},
+	{
+		Day: 13,
+		Part1: func(r io.Reader) (string, error) {
+			packets, err := ParsePackets(r)
+			if err != nil {
+				return "", err
+			}
+			sum, err := SumOrderedPairs(packets)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(sum), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			packets, err := ParsePackets(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(DecoderKey(packets)), nil
+		},
+	},
+	{
+		Day: 14,
+		Part1: func(r io.Reader) (string, error) {
+			cave, err := ParseCave(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(cave.Fill(SandAbyss)), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			cave, err := ParseCave(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(cave.Fill(SandFloor)), nil
+		},
+	},
+	{
+		Day: 15,
+		Part1: func(r io.Reader) (string, error) {
+			sensors, err := ParseSensors(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(ExcludedPositions(sensors, DistressRow)), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			sensors, err := ParseSensors(r)
+			if err != nil {
+				return "", err
+			}
+			beacon, ok := FindDistressBeacon(sensors, DistressBound)
+			if !ok {
+				return "", errors.New("every position is covered by a sensor")
+			}
+			return strconv.Itoa(TuningFrequency(beacon)), nil
+		},
+	},
+	{
+		Day: 16,
+		Part1: func(r io.Reader) (string, error) {
+			network, err := parseValveNetwork(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(network.Plan(30, 1).Pressure), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			network, err := parseValveNetwork(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(network.Plan(26, 2).Pressure), nil
+		},
+	},
+	{
+		Day: 17,
+		Part1: func(r io.Reader) (string, error) {
+			jets, err := ParseJets(r)
+			if err != nil {
+				return "", err
+			}
+			height, err := TowerHeight(jets, 2022)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(height), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			jets, err := ParseJets(r)
+			if err != nil {
+				return "", err
+			}
+			height, err := TowerHeight(jets, 1_000_000_000_000)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(height), nil
+		},
+	},
+	{
+		Day: 18,
+		Part1: func(r io.Reader) (string, error) {
+			cubes, err := ParseCubes(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(SurfaceArea(cubes)), nil
+		},
+		Part2: func(r io.Reader) (string, error) {
+			cubes, err := ParseCubes(r)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(ExteriorSurfaceArea(cubes)), nil
+		},
+	},
 }
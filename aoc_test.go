@@ -1,22 +1,54 @@
 package aoc
 
 import (
-	"embed"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"io/fs"
+	"math"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/JeremyLoy/AdventOfCode2022/answer"
+	"github.com/JeremyLoy/AdventOfCode2022/data"
+	"github.com/JeremyLoy/AdventOfCode2022/input"
 )
 
-//go:embed data
-var testData embed.FS
+var ledger = func() *answer.Ledger {
+	f, err := data.FS.Open(data.AnswersFile)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	ledger, err := answer.Load(f)
+	if err != nil {
+		panic(err)
+	}
+	return ledger
+}()
+
+// MustAnswer returns the known correct answer from the ledger
+func MustAnswer(t testing.TB, day, part int) string {
+	t.Helper()
+	want, ok := ledger.Correct(day, part)
+	if !ok {
+		t.Fatalf("no correct answer in the ledger for day %v part %v", day, part)
+	}
+	return want
+}
 
-func MustOpen(t *testing.T, name string) fs.File {
+func MustOpen(t testing.TB, day int, v input.Variant) io.Reader {
 	t.Helper()
-	file, err := testData.Open(name)
+	r, err := input.Default().Open(day, v)
 	if err != nil {
 		t.Fatal(err)
 	}
-	return file
+	return r
 }
 
 func TestDay1CalorieCounting(t *testing.T) {
@@ -40,13 +72,13 @@ func TestDay1CalorieCounting(t *testing.T) {
 	})
 	t.Run("part 1", func(t *testing.T) {
 		t.Parallel()
-		day1 := MustOpen(t, "data/day1.txt")
+		day1 := MustOpen(t, 1, input.Real)
 		elves, err := GetElves(day1)
 		if err != nil {
 			t.Fatal(err)
 		}
 		largestElf := GetLargestElf(elves)
-		if largestElf.Calories != 69_310 {
+		if strconv.Itoa(largestElf.Calories) != MustAnswer(t, 1, 1) {
 			t.Errorf("incorrect count - got %v", largestElf.Calories)
 		}
 		if largestElf.Number != 178 {
@@ -67,14 +99,14 @@ func TestDay1CalorieCounting(t *testing.T) {
 	})
 	t.Run("part 2", func(t *testing.T) {
 		t.Parallel()
-		day1 := MustOpen(t, "data/day1.txt")
+		day1 := MustOpen(t, 1, input.Real)
 		elves, err := GetElves(day1)
 		if err != nil {
 			t.Fatal(err)
 		}
 		topThreeSum := SumThreeLargestElves(elves)
 
-		if topThreeSum != 206_104 {
+		if strconv.Itoa(topThreeSum) != MustAnswer(t, 1, 2) {
 			t.Errorf("incorrect sum - got %v", topThreeSum)
 		}
 	})
@@ -96,13 +128,13 @@ func TestDay2RockPaperScissors(t *testing.T) {
 	})
 	t.Run("part 1", func(t *testing.T) {
 		t.Parallel()
-		day2 := MustOpen(t, "data/day2.txt")
+		day2 := MustOpen(t, 2, input.Real)
 		strategyGuide, err := ParseStrategyGuide(day2)
 		if err != nil {
 			t.Fatal(err)
 		}
 		score := CalculateRPSScore(strategyGuide, ModeSelf)
-		if score != 14_827 {
+		if strconv.Itoa(score) != MustAnswer(t, 2, 1) {
 			t.Errorf("unexpected score %v", score)
 		}
 	})
@@ -119,13 +151,13 @@ func TestDay2RockPaperScissors(t *testing.T) {
 	})
 	t.Run("part 2", func(t *testing.T) {
 		t.Parallel()
-		day2 := MustOpen(t, "data/day2.txt")
+		day2 := MustOpen(t, 2, input.Real)
 		strategyGuide, err := ParseStrategyGuide(day2)
 		if err != nil {
 			t.Fatal(err)
 		}
 		score := CalculateRPSScore(strategyGuide, ModeOutcome)
-		if score != 13_889 {
+		if strconv.Itoa(score) != MustAnswer(t, 2, 2) {
 			t.Errorf("unexpected score %v", score)
 		}
 	})
@@ -146,12 +178,12 @@ func TestDay3RucksackReorganization(t *testing.T) {
 	})
 	t.Run("part 1", func(t *testing.T) {
 		t.Parallel()
-		day3 := MustOpen(t, "data/day3.txt")
+		day3 := MustOpen(t, 3, input.Real)
 		priority, err := SumPriority(day3)
 		if err != nil {
 			t.Fatal(err)
 		}
-		if priority != 8_176 {
+		if strconv.Itoa(priority) != MustAnswer(t, 3, 1) {
 			t.Errorf("unexpected priority %v", priority)
 		}
 	})
@@ -167,12 +199,12 @@ func TestDay3RucksackReorganization(t *testing.T) {
 	})
 	t.Run("part 2", func(t *testing.T) {
 		t.Parallel()
-		day3 := MustOpen(t, "data/day3.txt")
+		day3 := MustOpen(t, 3, input.Real)
 		priority, err := SumBadgePriority(day3)
 		if err != nil {
 			t.Fatal(err)
 		}
-		if priority != 2_689 {
+		if strconv.Itoa(priority) != MustAnswer(t, 3, 2) {
 			t.Errorf("unexpected priority %v", priority)
 		}
 	})
@@ -194,13 +226,13 @@ func TestDay4CampCleanup(t *testing.T) {
 	})
 	t.Run("part 1", func(t *testing.T) {
 		t.Parallel()
-		day4 := MustOpen(t, "data/day4.txt")
+		day4 := MustOpen(t, 4, input.Real)
 		assignments, err := ParseAssignments(day4)
 		if err != nil {
 			t.Fatal(err)
 		}
 		sum := SumFullyOverlaps(assignments)
-		if sum != 466 {
+		if strconv.Itoa(sum) != MustAnswer(t, 4, 1) {
 			t.Errorf("unexpected sum of fully overlaps %v", sum)
 		}
 	})
@@ -217,13 +249,13 @@ func TestDay4CampCleanup(t *testing.T) {
 	})
 	t.Run("part 2", func(t *testing.T) {
 		t.Parallel()
-		day4 := MustOpen(t, "data/day4.txt")
+		day4 := MustOpen(t, 4, input.Real)
 		assignments, err := ParseAssignments(day4)
 		if err != nil {
 			t.Fatal(err)
 		}
 		sum := SumOverlappingSections(assignments)
-		if sum != 865 {
+		if strconv.Itoa(sum) != MustAnswer(t, 4, 2) {
 			t.Errorf("unexpected sum of overlapping sections %v", sum)
 		}
 	})
@@ -231,9 +263,22 @@ func TestDay4CampCleanup(t *testing.T) {
 
 func TestDay5SupplyStacks(t *testing.T) {
 	t.Parallel()
+	t.Run("empty stack", func(t *testing.T) {
+		t.Parallel()
+		stacks, steps, err := ParseStacksAndSteps(strings.NewReader("[A]     [C]\n 1   2   3 \n\nmove 1 from 3 to 1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if message := SumTopOfStacks(stacks); message != "A C" {
+			t.Errorf("unexpected message %q", message)
+		}
+		if message := SumTopOfStacks(ProcessSteps(stacks, steps)); message != "C  " {
+			t.Errorf("unexpected message %q", message)
+		}
+	})
 	t.Run("part 1 example", func(t *testing.T) {
 		t.Parallel()
-		day5Example := MustOpen(t, "data/day5Example.txt")
+		day5Example := MustOpen(t, 5, input.Example)
 		stacks, steps, err := ParseStacksAndSteps(day5Example)
 		if err != nil {
 			t.Fatal(err)
@@ -246,20 +291,20 @@ func TestDay5SupplyStacks(t *testing.T) {
 	})
 	t.Run("part 1", func(t *testing.T) {
 		t.Parallel()
-		day5 := MustOpen(t, "data/day5.txt")
+		day5 := MustOpen(t, 5, input.Real)
 		stacks, steps, err := ParseStacksAndSteps(day5)
 		if err != nil {
 			t.Fatal(err)
 		}
 		stacks = ProcessSteps(stacks, steps)
 		message := SumTopOfStacks(stacks)
-		if message != "DHBJQJCCW" {
+		if message != MustAnswer(t, 5, 1) {
 			t.Errorf("unexpected message %v", message)
 		}
 	})
 	t.Run("part 2 example", func(t *testing.T) {
 		t.Parallel()
-		day5Example := MustOpen(t, "data/day5Example.txt")
+		day5Example := MustOpen(t, 5, input.Example)
 		stacks, steps, err := ParseStacksAndSteps(day5Example)
 		if err != nil {
 			t.Fatal(err)
@@ -272,14 +317,14 @@ func TestDay5SupplyStacks(t *testing.T) {
 	})
 	t.Run("part 2", func(t *testing.T) {
 		t.Parallel()
-		day5 := MustOpen(t, "data/day5.txt")
+		day5 := MustOpen(t, 5, input.Real)
 		stacks, steps, err := ParseStacksAndSteps(day5)
 		if err != nil {
 			t.Fatal(err)
 		}
 		stacks = ProcessSteps9001(stacks, steps)
 		message := SumTopOfStacks(stacks)
-		if message != "WJVRLSJJT" {
+		if message != MustAnswer(t, 5, 2) {
 			t.Errorf("unexpected message %v", message)
 		}
 	})
@@ -307,8 +352,8 @@ func TestDay6TuningTrouble(t *testing.T) {
 	})
 	t.Run("part 1", func(t *testing.T) {
 		t.Parallel()
-		day6 := MustOpen(t, "data/day6.txt")
-		if start := StartOfPacket(day6); start != 1042 {
+		day6 := MustOpen(t, 6, input.Real)
+		if start := StartOfPacket(day6); strconv.Itoa(start) != MustAnswer(t, 6, 1) {
 			t.Errorf("unexpected start %v", start)
 		}
 	})
@@ -332,8 +377,8 @@ func TestDay6TuningTrouble(t *testing.T) {
 	})
 	t.Run("part 2", func(t *testing.T) {
 		t.Parallel()
-		day6 := MustOpen(t, "data/day6.txt")
-		if start := StartOfMessage(day6); start != 2980 {
+		day6 := MustOpen(t, 6, input.Real)
+		if start := StartOfMessage(day6); strconv.Itoa(start) != MustAnswer(t, 6, 2) {
 			t.Errorf("unexpected start %v", start)
 		}
 	})
@@ -343,7 +388,7 @@ func TestDay7NoSpaceLeftOnDevice(t *testing.T) {
 	t.Parallel()
 	t.Run("part 1 example", func(t *testing.T) {
 		t.Parallel()
-		day7example := MustOpen(t, "data/day7example.txt")
+		day7example := MustOpen(t, 7, input.Example)
 		root, err := ParseFS(day7example)
 		if err != nil {
 			t.Fatal(err)
@@ -356,20 +401,20 @@ func TestDay7NoSpaceLeftOnDevice(t *testing.T) {
 	})
 	t.Run("part 1", func(t *testing.T) {
 		t.Parallel()
-		day7 := MustOpen(t, "data/day7.txt")
+		day7 := MustOpen(t, 7, input.Real)
 		root, err := ParseFS(day7)
 		if err != nil {
 			t.Fatal(err)
 		}
 		dirs := root.GetDirs()
 		totalSize := SumDirSize(dirs)
-		if totalSize != 1_297_683 {
+		if strconv.Itoa(totalSize) != MustAnswer(t, 7, 1) {
 			t.Errorf("unexpected total size %v", totalSize)
 		}
 	})
 	t.Run("part 2 example", func(t *testing.T) {
 		t.Parallel()
-		day7example := MustOpen(t, "data/day7example.txt")
+		day7example := MustOpen(t, 7, input.Example)
 		root, err := ParseFS(day7example)
 		if err != nil {
 			t.Fatal(err)
@@ -382,16 +427,93 @@ func TestDay7NoSpaceLeftOnDevice(t *testing.T) {
 	})
 	t.Run("part 2", func(t *testing.T) {
 		t.Parallel()
-		day7 := MustOpen(t, "data/day7.txt")
+		day7 := MustOpen(t, 7, input.Real)
 		root, err := ParseFS(day7)
 		if err != nil {
 			t.Fatal(err)
 		}
 		deletedSize := SmallestDirToDelete(root)
-		if deletedSize != 5_756_764 {
+		if strconv.Itoa(deletedSize) != MustAnswer(t, 7, 2) {
 			t.Errorf("unexpected deleted size of %v", deletedSize)
 		}
 	})
+	t.Run("transcript round trip", func(t *testing.T) {
+		t.Parallel()
+		for _, name := range []input.Variant{input.Example, input.Real} {
+			root, err := ParseFS(MustOpen(t, 7, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var first, second bytes.Buffer
+			if err := WriteTranscript(&first, root); err != nil {
+				t.Fatal(err)
+			}
+			reparsed, err := ParseFS(bytes.NewReader(first.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := WriteTranscript(&second, reparsed); err != nil {
+				t.Fatal(err)
+			}
+			if first.String() != second.String() {
+				t.Errorf("%v: transcript did not round trip", name)
+			}
+			if reparsed.Size() != root.Size() {
+				t.Errorf("%v: unexpected size %v, should have been %v", name, reparsed.Size(), root.Size())
+			}
+			if len(reparsed.GetDirs()) != len(root.GetDirs()) {
+				t.Errorf("%v: unexpected dir count %v", name, len(reparsed.GetDirs()))
+			}
+		}
+	})
+	t.Run("unwritable transcripts", func(t *testing.T) {
+		t.Parallel()
+		for name, build := range map[string]func() *File{
+			"duplicate": func() *File {
+				root := NewDir("/")
+				root.Add(NewFile("a", 1), NewDir("a"))
+				return root
+			},
+			"dot dot":  func() *File { root := NewDir("/"); root.Add(NewDir("..")); return root },
+			"slash":    func() *File { root := NewDir("/"); root.Add(NewFile("/", 1)); return root },
+			"newline":  func() *File { root := NewDir("/"); root.Add(NewFile("a\nb", 1)); return root },
+			"negative": func() *File { root := NewDir("/"); root.Add(NewFile("a", -5)); return root },
+			"file with children": func() *File {
+				root, file := NewDir("/"), NewFile("a", 1)
+				file.Add(NewFile("b", 2))
+				root.Add(file)
+				return root
+			},
+			"file root": func() *File { return NewFile("/", 1) },
+		} {
+			var buf bytes.Buffer
+			if err := WriteTranscript(&buf, build()); err == nil || buf.Len() != 0 {
+				t.Errorf("%v: expected error and no output, got %v and %q", name, err, buf.String())
+			}
+		}
+	})
+	t.Run("synthetic transcript", func(t *testing.T) {
+		t.Parallel()
+		root := NewDir("/")
+		a := NewDir("a")
+		a.Add(NewFile("b.txt", 100), NewFile("c.txt", 200))
+		root.Add(a, NewFile("d", 50))
+		var buf bytes.Buffer
+		if err := WriteTranscript(&buf, root); err != nil {
+			t.Fatal(err)
+		}
+		want := "$ cd /\n$ ls\ndir a\n50 d\n$ cd a\n$ ls\n100 b.txt\n200 c.txt\n$ cd ..\n"
+		if buf.String() != want {
+			t.Errorf("unexpected transcript %q", buf.String())
+		}
+		parsed, err := ParseFS(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Size() != 350 {
+			t.Errorf("unexpected size %v", parsed.Size())
+		}
+	})
 }
 
 func TestDay8TreetopTreeHouse(t *testing.T) {
@@ -411,13 +533,13 @@ func TestDay8TreetopTreeHouse(t *testing.T) {
 	})
 	t.Run("part 1", func(t *testing.T) {
 		t.Parallel()
-		day8 := MustOpen(t, "data/day8.txt")
+		day8 := MustOpen(t, 8, input.Real)
 		grid, err := ParseGrid(day8)
 		if err != nil {
 			t.Fatal(err)
 		}
 		visible, _ := CountVisibleAndScore(grid)
-		if visible != 1_560 {
+		if strconv.Itoa(visible) != MustAnswer(t, 8, 1) {
 			t.Errorf("unexpected visible count %v", visible)
 		}
 	})
@@ -435,14 +557,1550 @@ func TestDay8TreetopTreeHouse(t *testing.T) {
 	})
 	t.Run("part 2", func(t *testing.T) {
 		t.Parallel()
-		day8 := MustOpen(t, "data/day8.txt")
+		day8 := MustOpen(t, 8, input.Real)
 		grid, err := ParseGrid(day8)
 		if err != nil {
 			t.Fatal(err)
 		}
 		_, score := CountVisibleAndScore(grid)
-		if score != 252_000 {
+		if strconv.Itoa(score) != MustAnswer(t, 8, 2) {
 			t.Errorf("unexpected visibity score %v", score)
 		}
 	})
+	t.Run("tree views example", func(t *testing.T) {
+		t.Parallel()
+		grid, err := ParseGrid(strings.NewReader(day8example))
+		if err != nil {
+			t.Fatal(err)
+		}
+		views := TreeViews(grid)
+		want := TreeView{Visible: true, Up: 2, Right: 2, Down: 1, Left: 2, Score: 8}
+		if view := views.At(Point{2, 3}); view != want {
+			t.Errorf("unexpected view %+v", view)
+		}
+		want = TreeView{Visible: false, Up: 1, Right: 1, Down: 1, Left: 1, Score: 1}
+		if view := views.At(Point{3, 1}); view != want {
+			t.Errorf("unexpected view %+v", view)
+		}
+	})
+	t.Run("queries example", func(t *testing.T) {
+		t.Parallel()
+		grid, err := ParseGrid(strings.NewReader(day8example))
+		if err != nil {
+			t.Fatal(err)
+		}
+		visible := VisibleTrees(grid)
+		if visible.Len() != 21 {
+			t.Errorf("unexpected visible count %v", visible.Len())
+		}
+		if !visible.Contains(Point{1, 1}) {
+			t.Errorf("expected %v to be visible", Point{1, 1})
+		}
+		top := TopScenicTrees(grid, 2)
+		if len(top) != 2 || top[0] != (ScenicTree{Point: Point{2, 3}, Score: 8}) {
+			t.Errorf("unexpected top trees %+v", top)
+		}
+		for _, k := range []int{0, -1} {
+			if top := TopScenicTrees(grid, k); len(top) != 0 {
+				t.Errorf("unexpected top %v trees %+v", k, top)
+			}
+		}
+		if distance, clear := LineOfSight(grid, Point{2, 3}, Up); distance != 2 || clear {
+			t.Errorf("unexpected line of sight %v %v", distance, clear)
+		}
+		if distance, clear := LineOfSight(grid, Point{2, 3}, Left); distance != 2 || !clear {
+			t.Errorf("unexpected line of sight %v %v", distance, clear)
+		}
+		if distance, clear := LineOfSight(grid, Point{5, 5}, Left); distance != 0 || clear {
+			t.Errorf("unexpected line of sight from off the grid %v %v", distance, clear)
+		}
+	})
+}
+
+func TestGrid(t *testing.T) {
+	t.Parallel()
+	grid, err := ParseGrid(strings.NewReader("123\n456"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grid.Width != 3 || grid.Height != 2 {
+		t.Fatalf("unexpected dimensions %vx%v", grid.Width, grid.Height)
+	}
+	if v := grid.At(Point{2, 1}); v != 6 {
+		t.Errorf("unexpected value %v", v)
+	}
+	if _, ok := grid.Get(Point{3, 0}); ok {
+		t.Errorf("expected point to be out of bounds")
+	}
+	if n := grid.Neighbors(Point{0, 0}); len(n) != 2 {
+		t.Errorf("unexpected neighbors %v", n)
+	}
+	if row := grid.Row(1); fmt.Sprint(row) != "[4 5 6]" {
+		t.Errorf("unexpected row %v", row)
+	}
+	if column := grid.Column(1); fmt.Sprint(column) != "[2 5]" {
+		t.Errorf("unexpected column %v", column)
+	}
+	grid.Row(0)[0] = 9
+	if v := grid.At(Point{0, 0}); v != 9 {
+		t.Errorf("row should be a view into the grid, got %v", v)
+	}
+	alphanumeric, err := ParseGridAlphabet(strings.NewReader("09\naz"), AlphanumericHeights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(alphanumeric.Cells) != "[0 9 10 35]" {
+		t.Errorf("unexpected heights %v", alphanumeric.Cells)
+	}
+	for _, tc := range []struct {
+		input        string
+		line, column int
+	}{
+		{"123\n45", 2, 3},
+		{"123\n4567", 2, 4},
+		{"123\n4x6", 2, 2},
+		{"12-", 1, 3},
+		{"\n123", 1, 1},
+	} {
+		_, err := ParseGrid(strings.NewReader(tc.input))
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%q: expected a syntax error, got %v", tc.input, err)
+			continue
+		}
+		if syntaxErr.Line != tc.line || syntaxErr.Column != tc.column {
+			t.Errorf("%q: unexpected position %v", tc.input, syntaxErr)
+		}
+	}
+	if _, err := ParseGrid(strings.NewReader("")); err == nil {
+		t.Errorf("expected error for empty grid")
+	}
+}
+
+func TestSet(t *testing.T) {
+	t.Parallel()
+	a := NewSet(1, 2, 3)
+	b := NewSet(3, 4)
+	if !a.Contains(2) || a.Contains(4) {
+		t.Errorf("unexpected membership %v", a)
+	}
+	if union := Sorted(a.Union(b)); fmt.Sprint(union) != "[1 2 3 4]" {
+		t.Errorf("unexpected union %v", union)
+	}
+	if intersection := Sorted(a.Intersection(b)); fmt.Sprint(intersection) != "[3]" {
+		t.Errorf("unexpected intersection %v", intersection)
+	}
+	if difference := Sorted(a.Difference(b)); fmt.Sprint(difference) != "[1 2]" {
+		t.Errorf("unexpected difference %v", difference)
+	}
+	if difference := Sorted(a.SymmetricDifference(b)); fmt.Sprint(difference) != "[1 2 4]" {
+		t.Errorf("unexpected symmetric difference %v", difference)
+	}
+	if !NewSet(1, 3).IsSubset(a) || a.IsSubset(b) || !a.IsSuperset(NewSet(1)) {
+		t.Errorf("unexpected subset result")
+	}
+	clone := a.Clone()
+	clone.Delete(1)
+	if !a.Contains(1) || clone.Contains(1) {
+		t.Errorf("clone should not share storage")
+	}
+	if !a.Equal(NewSet(3, 2, 1)) || a.Equal(clone) {
+		t.Errorf("unexpected equality result")
+	}
+	a.Clear()
+	if a.Len() != 0 {
+		t.Errorf("unexpected length after clear %v", a.Len())
+	}
+}
+
+func TestByteSet(t *testing.T) {
+	// not parallel, AllocsPerRun requires it
+	a := NewByteSet('a', 'b', 'z', 200)
+	b := NewByteSet('b', 'c')
+	if !a.Contains(200) || a.Contains('c') || a.Len() != 4 {
+		t.Errorf("unexpected membership %v", a.Slice())
+	}
+	if union := a.Union(b).Slice(); string(union[:4]) != "abcz" || len(union) != 5 {
+		t.Errorf("unexpected union %v", union)
+	}
+	if intersection := a.Intersection(b).Slice(); string(intersection) != "b" {
+		t.Errorf("unexpected intersection %v", intersection)
+	}
+	if difference := b.Difference(a).Slice(); string(difference) != "c" {
+		t.Errorf("unexpected difference %v", difference)
+	}
+	if difference := NewByteSet('a', 'b').SymmetricDifference(b).Slice(); string(difference) != "ac" {
+		t.Errorf("unexpected symmetric difference %v", difference)
+	}
+	if !NewByteSet('a').IsSubset(a) || b.IsSubset(a) || !a.IsSuperset(NewByteSet('z')) {
+		t.Errorf("unexpected subset result")
+	}
+	if min, ok := a.Min(); !ok || min != 'a' {
+		t.Errorf("unexpected min %v", min)
+	}
+	a.Delete('a')
+	if a.Contains('a') || !a.Equal(NewByteSet('b', 'z', 200)) {
+		t.Errorf("unexpected set after delete %v", a.Slice())
+	}
+	a.Clear()
+	if _, ok := a.Min(); ok || a.Len() != 0 {
+		t.Errorf("unexpected set after clear %v", a.Slice())
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		Priority("vJrwpWtwJgWrhcsFMMfFFhFp")
+	})
+	if allocs != 0 {
+		t.Errorf("Priority should not allocate, got %v allocations", allocs)
+	}
+}
+
+func TestStack(t *testing.T) {
+	t.Parallel()
+	var s Stack[int]
+	if _, ok := s.TryPop(); ok {
+		t.Errorf("expected pop on empty stack to fail")
+	}
+	s.Push(1, 2, 3, 4)
+	if top := s.PeekN(2); fmt.Sprint(top) != "[3 4]" {
+		t.Errorf("unexpected peek %v", top)
+	}
+	popped := s.PopN(2)
+	s.Push(5)
+	if fmt.Sprint(popped) != "[3 4]" {
+		t.Errorf("popped elements were overwritten by push, got %v", popped)
+	}
+	if _, ok := s.TryPopN(4); ok {
+		t.Errorf("expected pop of more than the stack holds to fail")
+	}
+	clone := s.Clone()
+	if reversed := s.PopNReversed(3); fmt.Sprint(reversed) != "[5 2 1]" {
+		t.Errorf("unexpected reversed pop %v", reversed)
+	}
+	if s.Len() != 0 || clone.Len() != 3 || clone.Peek() != 5 {
+		t.Errorf("clone should not share storage, got %v", *clone)
+	}
+}
+
+func TestDeque(t *testing.T) {
+	t.Parallel()
+	var d Deque[int]
+	if _, ok := d.TryPopFront(); ok {
+		t.Errorf("expected pop on empty deque to fail")
+	}
+	for i := 0; i < 10; i++ {
+		d.PushBack(i)
+		d.PushFront(-i)
+	}
+	if d.Len() != 20 || d.At(0) != -9 || d.At(19) != 9 {
+		t.Errorf("unexpected deque %v", d.Slice())
+	}
+	clone := d.Clone()
+	for i := 9; i >= 0; i-- {
+		if back := d.PopBack(); back != i {
+			t.Errorf("unexpected back %v, should have been %v", back, i)
+		}
+		if front := d.PopFront(); front != -i {
+			t.Errorf("unexpected front %v, should have been %v", front, -i)
+		}
+	}
+	if _, ok := d.TryPopBack(); ok {
+		t.Errorf("expected pop on empty deque to fail")
+	}
+	if clone.Len() != 20 {
+		t.Errorf("clone should not share storage, got %v", clone.Slice())
+	}
+}
+
+func TestPoint(t *testing.T) {
+	t.Parallel()
+	p, q := Point{1, 2}, Point{4, -2}
+	if sum := p.Add(q); sum != (Point{5, 0}) {
+		t.Errorf("unexpected sum %v", sum)
+	}
+	if difference := q.Sub(p); difference != (Point{3, -4}) {
+		t.Errorf("unexpected difference %v", difference)
+	}
+	if scaled := p.Scale(-2); scaled != (Point{-2, -4}) {
+		t.Errorf("unexpected scaled %v", scaled)
+	}
+	if d := p.Manhattan(q); d != 7 {
+		t.Errorf("unexpected manhattan distance %v", d)
+	}
+	if d := p.Chebyshev(q); d != 4 {
+		t.Errorf("unexpected chebyshev distance %v", d)
+	}
+	for _, n := range p.Neighbors8() {
+		if p.Chebyshev(n) != 1 {
+			t.Errorf("unexpected neighbor %v", n)
+		}
+	}
+	if n := p.Neighbors4(); n[0] != (Point{1, 1}) || n[1] != (Point{2, 2}) {
+		t.Errorf("unexpected neighbors %v", n)
+	}
+	if d := Up.RotateRight(); d != Right {
+		t.Errorf("unexpected rotation %v", d)
+	}
+	if d := Up.RotateLeft(); d != Left {
+		t.Errorf("unexpected rotation %v", d)
+	}
+	if d := Left.Opposite(); d != Right || d.Delta() != (Point{1, 0}) {
+		t.Errorf("unexpected opposite %v", d)
+	}
+	for name, f := range map[string]func(Direction){
+		"Delta":       func(d Direction) { d.Delta() },
+		"RotateRight": func(d Direction) { d.RotateRight() },
+		"RotateLeft":  func(d Direction) { d.RotateLeft() },
+		"Opposite":    func(d Direction) { d.Opposite() },
+	} {
+		for _, d := range []Direction{-1, 4, 7} {
+			func() {
+				defer func() {
+					if r := recover(); r != fmt.Sprintf("invalid direction %v", d) {
+						t.Errorf("%v of %v: unexpected panic %v", name, d, r)
+					}
+				}()
+				f(d)
+			}()
+		}
+	}
+	box := BoundingBox(p, q, Point{0, 5})
+	if box != (Rect{Point{0, -2}, Point{4, 5}}) || box.Width() != 5 || box.Height() != 8 {
+		t.Errorf("unexpected bounding box %v", box)
+	}
+	if !box.Contains(Point{2, 2}) || box.Contains(Point{5, 0}) {
+		t.Errorf("unexpected containment for %v", box)
+	}
+	a, b := Point3{1, 2, 3}, Point3{0, 0, 0}
+	if d := a.Manhattan(b); d != 6 {
+		t.Errorf("unexpected 3d manhattan distance %v", d)
+	}
+	if d := a.Chebyshev(b); d != 3 {
+		t.Errorf("unexpected 3d chebyshev distance %v", d)
+	}
+	for _, n := range a.Neighbors6() {
+		if a.Manhattan(n) != 1 {
+			t.Errorf("unexpected 3d neighbor %v", n)
+		}
+	}
+	cuboid := BoundingCuboid(a, b, Point3{-1, 4, 2})
+	if cuboid != (Cuboid{Point3{-1, 0, 0}, Point3{1, 4, 3}}) || BoundingCuboid() != (Cuboid{}) {
+		t.Errorf("unexpected bounding cuboid %v", cuboid)
+	}
+	if !cuboid.Contains(Point3{0, 4, 1}) || cuboid.Contains(Point3{0, 5, 1}) || !cuboid.Grow(1).Contains(Point3{0, 5, 1}) {
+		t.Errorf("unexpected containment for %v", cuboid)
+	}
+}
+
+func TestPuzzles(t *testing.T) {
+	t.Parallel()
+	for _, puzzle := range Puzzles {
+		puzzle := puzzle
+		t.Run(fmt.Sprintf("day %v", puzzle.Day), func(t *testing.T) {
+			t.Parallel()
+			in, err := input.Default().Bytes(puzzle.Day, input.Real)
+			if errors.Is(err, fs.ErrNotExist) {
+				t.Skip(err)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for part := 1; part <= 2; part++ {
+				got, err := puzzle.Part(part)(bytes.NewReader(in))
+				if result := ledger.Check(puzzle.Day, part, got, err); !result.OK() {
+					t.Error(result)
+				}
+			}
+		})
+	}
+}
+
+func MustBytes(t testing.TB, day int, v input.Variant) []byte {
+	t.Helper()
+	in, err := input.Default().Bytes(day, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return in
+}
+
+func BenchmarkGetElves(b *testing.B) {
+	in := MustBytes(b, 1, input.Real)
+	for i := 0; i < b.N; i++ {
+		if _, err := GetElves(bytes.NewReader(in)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseStrategyGuide(b *testing.B) {
+	in := MustBytes(b, 2, input.Real)
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseStrategyGuide(bytes.NewReader(in)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCalculateRPSScore(b *testing.B) {
+	guide, err := ParseStrategyGuide(bytes.NewReader(MustBytes(b, 2, input.Real)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CalculateRPSScore(guide, ModeOutcome)
+	}
+}
+
+func BenchmarkSumPriority(b *testing.B) {
+	in := MustBytes(b, 3, input.Real)
+	for i := 0; i < b.N; i++ {
+		if _, err := SumPriority(bytes.NewReader(in)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSumBadgePriority(b *testing.B) {
+	in := MustBytes(b, 3, input.Real)
+	for i := 0; i < b.N; i++ {
+		if _, err := SumBadgePriority(bytes.NewReader(in)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseAssignments(b *testing.B) {
+	in := MustBytes(b, 4, input.Real)
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseAssignments(bytes.NewReader(in)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseStacksAndSteps(b *testing.B) {
+	in := MustBytes(b, 5, input.Real)
+	for i := 0; i < b.N; i++ {
+		if _, _, err := ParseStacksAndSteps(bytes.NewReader(in)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProcessSteps9001(b *testing.B) {
+	in := MustBytes(b, 5, input.Real)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		stacks, steps, err := ParseStacksAndSteps(bytes.NewReader(in))
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		ProcessSteps9001(stacks, steps)
+	}
+}
+
+func BenchmarkCommunicationDevice(b *testing.B) {
+	in := MustBytes(b, 6, input.Real)
+	for _, length := range []int{4, 14} {
+		b.Run(fmt.Sprint(length), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				communicationDevice(bytes.NewReader(in), length)
+			}
+		})
+	}
+}
+
+func BenchmarkParseFS(b *testing.B) {
+	in := MustBytes(b, 7, input.Real)
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseFS(bytes.NewReader(in)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParseFSWideDir lists one directory with many entries twice, which is quadratic if each entry scans its
+// siblings
+func BenchmarkParseFSWideDir(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString("$ cd /\n")
+	for listing := 0; listing < 2; listing++ {
+		sb.WriteString("$ ls\n")
+		for n := 0; n < 10_000; n++ {
+			fmt.Fprintf(&sb, "%v f%v\n", n, n)
+		}
+	}
+	in := []byte(sb.String())
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseFS(bytes.NewReader(in)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSmallestDirToDelete(b *testing.B) {
+	root, err := ParseFS(bytes.NewReader(MustBytes(b, 7, input.Real)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SmallestDirToDelete(root)
+	}
+}
+
+func BenchmarkParseGrid(b *testing.B) {
+	in := MustBytes(b, 8, input.Real)
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseGrid(bytes.NewReader(in)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCountVisibleAndScore(b *testing.B) {
+	grid, err := ParseGrid(bytes.NewReader(MustBytes(b, 8, input.Real)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CountVisibleAndScore(grid)
+	}
+}
+
+func TestParserErrors(t *testing.T) {
+	t.Parallel()
+	guide := func(r io.Reader) error { _, err := ParseStrategyGuide(r); return err }
+	priority := func(r io.Reader) error { _, err := SumPriority(r); return err }
+	assignments := func(r io.Reader) error { _, err := ParseAssignments(r); return err }
+	stacks := func(r io.Reader) error { _, _, err := ParseStacksAndSteps(r); return err }
+	parseFS := func(r io.Reader) error { _, err := ParseFS(r); return err }
+	jets := func(r io.Reader) error { _, err := ParseJets(r); return err }
+	for _, tc := range []struct {
+		name  string
+		parse func(io.Reader) error
+		input string
+	}{
+		{"unknown shape", guide, "A Y\nD X"},
+		{"no common item", priority, "abcd"},
+		{"backwards assignment", assignments, "4-2,6-8"},
+		{"missing stack", stacks, "[A]\n 1 \n\nmove 1 from 1 to 2"},
+		{"too many crates", stacks, "[A]    \n 1   2 \n\nmove 2 from 1 to 2"},
+		{"short command", parseFS, "$ cd /\n$ c"},
+		{"cd above root", parseFS, "$ cd /\n$ cd .."},
+		{"cd into file", parseFS, "$ cd /\n$ ls\n10 a\n$ cd a"},
+		{"invalid jet", jets, "<>x<"},
+		{"no jets", jets, "\n"},
+	} {
+		if err := tc.parse(strings.NewReader(tc.input)); err == nil {
+			t.Errorf("%v: expected error for %q", tc.name, tc.input)
+		}
+	}
+}
+
+func TestDay9RopeBridge(t *testing.T) {
+	t.Parallel()
+	exampleMotions := "R 4\nU 4\nL 3\nD 1\nR 4\nD 1\nL 5\nR 2"
+	largerExampleMotions := "R 5\nU 8\nL 8\nD 3\nR 17\nD 10\nL 25\nU 20"
+	t.Run("part 1 example", func(t *testing.T) {
+		t.Parallel()
+		motions, err := ParseMotions(strings.NewReader(exampleMotions))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if visits := TailVisits(motions, 2); visits != 13 {
+			t.Errorf("unexpected tail visits %v", visits)
+		}
+	})
+	t.Run("part 2 example", func(t *testing.T) {
+		t.Parallel()
+		motions, err := ParseMotions(strings.NewReader(exampleMotions))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if visits := TailVisits(motions, 10); visits != 1 {
+			t.Errorf("unexpected tail visits %v", visits)
+		}
+		motions, err = ParseMotions(strings.NewReader(largerExampleMotions))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if visits := TailVisits(motions, 10); visits != 36 {
+			t.Errorf("unexpected tail visits %v", visits)
+		}
+	})
+	t.Run("every knot", func(t *testing.T) {
+		t.Parallel()
+		motions, err := ParseMotions(strings.NewReader(exampleMotions))
+		if err != nil {
+			t.Fatal(err)
+		}
+		visited := SimulateRope(motions, 10)
+		if len(visited) != 10 {
+			t.Fatalf("unexpected knot count %v", len(visited))
+		}
+		// the first knot behind the head moves the same no matter how long the rope is
+		if n := visited[1].Len(); n != 13 {
+			t.Errorf("unexpected visits for knot 1 %v", n)
+		}
+		if !visited[0].Contains(Point{4, -4}) {
+			t.Errorf("expected the head to visit %v", Point{4, -4})
+		}
+	})
+	t.Run("parse errors", func(t *testing.T) {
+		t.Parallel()
+		for _, in := range []string{"X 4", "R", "R -1", "R 4 junk", "RR 4", "R 4x"} {
+			if _, err := ParseMotions(strings.NewReader(in)); err == nil {
+				t.Errorf("expected error for %q", in)
+			}
+		}
+	})
+}
+
+func TestDay10CathodeRayTube(t *testing.T) {
+	t.Parallel()
+	t.Run("trace example", func(t *testing.T) {
+		t.Parallel()
+		program, err := ParseProgram(strings.NewReader("noop\naddx 3\naddx -5"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		trace := Execute(program)
+		if trace.Cycles() != 5 {
+			t.Errorf("unexpected cycle count %v", trace.Cycles())
+		}
+		for cycle, want := range []int{1, 1, 1, 4, 4, -1} {
+			if x := trace.During(cycle + 1); x != want {
+				t.Errorf("unexpected X %v during cycle %v", x, cycle+1)
+			}
+		}
+		if strength, err := SumSignalStrength(trace, []int{2, 4, 100}); err != nil || strength != 2+16-100 {
+			t.Errorf("unexpected signal strength %v, %v", strength, err)
+		}
+		for _, cycle := range []int{0, -1} {
+			if _, err := SumSignalStrength(trace, []int{20, cycle}); err == nil {
+				t.Errorf("expected error for cycle %v", cycle)
+			}
+		}
+	})
+	t.Run("larger example", func(t *testing.T) {
+		t.Parallel()
+		program, err := ParseProgram(MustOpen(t, 10, input.Example))
+		if err != nil {
+			t.Fatal(err)
+		}
+		trace := Execute(program)
+		if trace.Cycles() != CRTWidth*CRTHeight {
+			t.Errorf("unexpected cycle count %v", trace.Cycles())
+		}
+		for i, want := range []int{420, 1140, 1800, 2940, 2880, 3960} {
+			cycle := DefaultSignalCycles[i]
+			if strength := cycle * trace.During(cycle); strength != want {
+				t.Errorf("unexpected signal strength %v during cycle %v", strength, cycle)
+			}
+		}
+		if strength, err := SumSignalStrength(trace, DefaultSignalCycles); err != nil || strength != 13140 {
+			t.Errorf("unexpected signal strength %v, %v", strength, err)
+		}
+		want := strings.Join([]string{
+			"##..##..##..##..##..##..##..##..##..##..",
+			"###...###...###...###...###...###...###.",
+			"####....####....####....####....####....",
+			"#####.....#####.....#####.....#####.....",
+			"######......######......######......####",
+			"#######.......#######.......#######.....",
+		}, "\n") + "\n"
+		if image := RenderCRT(trace); image != want {
+			t.Errorf("unexpected image\n%v", image)
+		}
+	})
+	t.Run("render", func(t *testing.T) {
+		t.Parallel()
+		// X never moves from 1, so the sprite covers the first three pixels of every row
+		image := RenderCRT(Execute(nil))
+		row := "###" + strings.Repeat(".", CRTWidth-3) + "\n"
+		if image != strings.Repeat(row, CRTHeight) {
+			t.Errorf("unexpected image\n%v", image)
+		}
+		if _, err := ReadCRT(image); err == nil {
+			t.Errorf("expected error reading an image without letters")
+		}
+	})
+	t.Run("read", func(t *testing.T) {
+		t.Parallel()
+		glyphs := map[rune]string{}
+		for glyph, letter := range letters {
+			glyphs[letter] = glyph
+		}
+		for _, want := range []string{"ABCEFGHI", "JKLOPRSU", "ZEBRAFUL"} {
+			rows := make([]string, CRTHeight)
+			for _, letter := range want {
+				for y := range rows {
+					rows[y] += glyphs[letter][y*4:y*4+4] + "."
+				}
+			}
+			text, err := ReadCRT(strings.Join(rows, "\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if text != want {
+				t.Errorf("unexpected text %v, should have been %v", text, want)
+			}
+		}
+	})
+	t.Run("parse errors", func(t *testing.T) {
+		t.Parallel()
+		for _, in := range []string{"addx", "addx x", "mul 2", "noop 1"} {
+			if _, err := ParseProgram(strings.NewReader(in)); err == nil {
+				t.Errorf("expected error for %q", in)
+			}
+		}
+	})
+}
+
+func TestDay11MonkeyInTheMiddle(t *testing.T) {
+	t.Parallel()
+	t.Run("part 1 example", func(t *testing.T) {
+		t.Parallel()
+		monkeys, err := ParseMonkeys(MustOpen(t, 11, input.Example))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SimulateMonkeys(monkeys, 20, ReliefDivide); err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range []int{101, 95, 7, 105} {
+			if monkeys[i].Inspections != want {
+				t.Errorf("monkey %v inspected %v items, should have been %v", i, monkeys[i].Inspections, want)
+			}
+		}
+		if business := MonkeyBusiness(monkeys); business != 10_605 {
+			t.Errorf("unexpected monkey business %v", business)
+		}
+	})
+	t.Run("part 2 example", func(t *testing.T) {
+		t.Parallel()
+		monkeys, err := ParseMonkeys(MustOpen(t, 11, input.Example))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SimulateMonkeys(monkeys, 10_000, ReliefModulo); err != nil {
+			t.Fatal(err)
+		}
+		if business := MonkeyBusiness(monkeys); business != 2_713_310_158 {
+			t.Errorf("unexpected monkey business %v", business)
+		}
+	})
+	t.Run("operations", func(t *testing.T) {
+		t.Parallel()
+		op, err := ParseOperation("new = old * old")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if worry, err := op.Apply(12); err != nil || worry != 144 {
+			t.Errorf("unexpected worry %v, %v", worry, err)
+		}
+		if op.String() != "new = old * old" {
+			t.Errorf("unexpected string %v", op)
+		}
+		if _, err := op.Apply(1 << 32); !errors.Is(err, ErrWorryOverflow) {
+			t.Errorf("expected overflow, got %v", err)
+		}
+		for _, in := range []string{"new = old / 2", "new = old *", "old = old + 1", "new = old + x"} {
+			if _, err := ParseOperation(in); err == nil {
+				t.Errorf("expected error for %q", in)
+			}
+		}
+	})
+	t.Run("divisors", func(t *testing.T) {
+		t.Parallel()
+		op, err := ParseOperation("new = old + 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, divisors := range [][]int{{3, 0}, {3, -5}, {math.MaxInt / 2, math.MaxInt/2 - 2}} {
+			monkeys := []*Monkey{
+				{Number: 0, Items: []int{1}, Operation: op, Divisor: divisors[0], IfTrue: 1, IfFalse: 1},
+				{Number: 1, Items: []int{1}, Operation: op, Divisor: divisors[1], IfTrue: 0, IfFalse: 0},
+			}
+			if err := SimulateMonkeys(monkeys, 1, ReliefModulo); err == nil {
+				t.Errorf("expected error for divisors %v", divisors)
+			}
+		}
+	})
+	t.Run("targets", func(t *testing.T) {
+		t.Parallel()
+		op, err := ParseOperation("new = old + 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, targets := range [][2]int{{5, 1}, {1, -1}, {0, 1}} {
+			monkeys := []*Monkey{
+				{Number: 0, Items: []int{1}, Operation: op, Divisor: 2, IfTrue: targets[0], IfFalse: targets[1]},
+				{Number: 1, Items: []int{1}, Operation: op, Divisor: 3, IfTrue: 0, IfFalse: 0},
+			}
+			if err := SimulateMonkeys(monkeys, 1, ReliefDivide); err == nil {
+				t.Errorf("expected error for targets %v", targets)
+			}
+		}
+		if business := MonkeyBusiness([]*Monkey{{Inspections: 7}}); business != 0 {
+			t.Errorf("unexpected business of one monkey %v", business)
+		}
+	})
+	t.Run("overflow without relief", func(t *testing.T) {
+		t.Parallel()
+		monkeys, err := ParseMonkeys(MustOpen(t, 11, input.Example))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SimulateMonkeys(monkeys, 10_000, ReliefNone); !errors.Is(err, ErrWorryOverflow) {
+			t.Errorf("expected overflow, got %v", err)
+		}
+	})
+}
+
+func TestDay12HillClimbing(t *testing.T) {
+	t.Parallel()
+	hm, err := ParseHeightMap(MustOpen(t, 12, input.Example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hm.Start != (Point{0, 0}) || hm.End != (Point{5, 2}) {
+		t.Fatalf("unexpected start %v and end %v", hm.Start, hm.End)
+	}
+	// valid checks that every step of path moves to a neighbor and climbs at most one
+	valid := func(t *testing.T, path []Point) {
+		t.Helper()
+		if path[len(path)-1] != hm.End {
+			t.Errorf("path ends at %v", path[len(path)-1])
+		}
+		for i := 1; i < len(path); i++ {
+			if path[i].Manhattan(path[i-1]) != 1 || hm.Grid.At(path[i]) > hm.Grid.At(path[i-1])+1 {
+				t.Errorf("invalid step from %v to %v", path[i-1], path[i])
+			}
+		}
+	}
+	t.Run("part 1 example", func(t *testing.T) {
+		t.Parallel()
+		path, ok := hm.Climb()
+		if !ok || len(path)-1 != 31 {
+			t.Fatalf("unexpected path of %v steps, %v", len(path)-1, ok)
+		}
+		if path[0] != hm.Start {
+			t.Errorf("path starts at %v", path[0])
+		}
+		valid(t, path)
+		render := hm.RenderPath(path)
+		if arrows := len(render) - strings.Count(render, ".") - strings.Count(render, "\n") - 1; arrows != 31 {
+			t.Errorf("unexpected %v arrows in\n%v", arrows, render)
+		}
+	})
+	t.Run("part 2 example", func(t *testing.T) {
+		t.Parallel()
+		path, ok := hm.Hike()
+		if !ok || len(path)-1 != 29 {
+			t.Fatalf("unexpected path of %v steps, %v", len(path)-1, ok)
+		}
+		if hm.Grid.At(path[0]) != 0 {
+			t.Errorf("path starts at height %v", hm.Grid.At(path[0]))
+		}
+		valid(t, path)
+	})
+	t.Run("unreachable", func(t *testing.T) {
+		t.Parallel()
+		cliff, err := ParseHeightMap(strings.NewReader("SacE"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path, ok := cliff.Climb(); ok {
+			t.Errorf("unexpected path %v", path)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		for _, in := range []string{"SabE1", "abcE", "SaES\nabcd", "SabE\nabc"} {
+			if _, err := ParseHeightMap(strings.NewReader(in)); err == nil {
+				t.Errorf("expected error for %q", in)
+			}
+		}
+	})
+}
+
+func TestDay13DistressSignal(t *testing.T) {
+	t.Parallel()
+	packets, err := ParsePackets(MustOpen(t, 13, input.Example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("part 1 example", func(t *testing.T) {
+		t.Parallel()
+		for i, want := range []int{-1, -1, 1, -1, 1, -1, 1, 1} {
+			if c := ComparePackets(packets[2*i], packets[2*i+1]); c != want {
+				t.Errorf("pair %v compared %v, should have been %v", i+1, c, want)
+			}
+		}
+		if sum, err := SumOrderedPairs(packets); err != nil || sum != 13 {
+			t.Errorf("unexpected sum %v, %v", sum, err)
+		}
+	})
+	t.Run("part 2 example", func(t *testing.T) {
+		t.Parallel()
+		if key := DecoderKey(packets); key != 140 {
+			t.Errorf("unexpected decoder key %v", key)
+		}
+		sorted := append([]Packet{}, packets...)
+		sorted = append(sorted, DividerPackets...)
+		SortPackets(sorted)
+		if sorted[0].String() != "[]" || sorted[9].String() != "[[2]]" || sorted[13].String() != "[[6]]" {
+			t.Errorf("unexpected order %v", sorted)
+		}
+	})
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+		for _, p := range packets {
+			reparsed, err := ParsePacket(p.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ComparePackets(p, reparsed) != 0 || reparsed.String() != p.String() {
+				t.Errorf("%v reparsed as %v", p, reparsed)
+			}
+		}
+		if c := ComparePackets(IntPacket(math.MinInt), IntPacket(1)); c != -1 {
+			t.Errorf("unexpected comparison %v of the smallest int with 1", c)
+		}
+		if c := ComparePackets(IntPacket(math.MaxInt), IntPacket(-1)); c != 1 {
+			t.Errorf("unexpected comparison %v of the largest int with -1", c)
+		}
+		if p := ListPacket(IntPacket(1), ListPacket(IntPacket(2), ListPacket(IntPacket(3)))); p.String() != "[1,[2,[3]]]" {
+			t.Errorf("unexpected string %v", p)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		for _, tc := range []struct {
+			in     string
+			column int
+		}{
+			{"", 1},
+			{"1", 1},
+			{"[1,2", 5},
+			{"[1,,2]", 4},
+			{"[,1]", 2},
+			{"[1,]", 4},
+			{"[1 2]", 3},
+			{"[1][2]", 4},
+			{"[1]]", 4},
+			{"[[1]2]", 5},
+			{"[a]", 2},
+			{"[99999999999999999999]", 2},
+		} {
+			_, err := ParsePacket(tc.in)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Errorf("expected syntax error for %q, got %v", tc.in, err)
+			} else if syntaxErr.Column != tc.column {
+				t.Errorf("%q: error at column %v, should have been %v: %v", tc.in, syntaxErr.Column, tc.column, err)
+			}
+		}
+		_, err := ParsePackets(strings.NewReader("[1]\n\n[2,]"))
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Line != 3 {
+			t.Errorf("expected syntax error on line 3, got %v", err)
+		}
+		_, err = ParsePackets(strings.NewReader("[1]\n  \t[2,]"))
+		if !errors.As(err, &syntaxErr) || syntaxErr.Line != 2 || syntaxErr.Column != 7 {
+			t.Errorf("expected syntax error on line 2, column 7, got %v", err)
+		}
+		if _, err := SumOrderedPairs(packets[:3]); err == nil {
+			t.Errorf("expected error for an odd number of packets")
+		}
+	})
+}
+
+func TestDay14RegolithReservoir(t *testing.T) {
+	t.Parallel()
+	example := "498,4 -> 498,6 -> 496,6\n503,4 -> 502,4 -> 502,9 -> 494,9"
+	t.Run("part 1 example", func(t *testing.T) {
+		t.Parallel()
+		cave, err := ParseCave(strings.NewReader(example))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cave.Bottom != 9 {
+			t.Errorf("unexpected bottom %v", cave.Bottom)
+		}
+		if p, ok := cave.Drop(SandAbyss); !ok || p != (Point{500, 8}) {
+			t.Errorf("first sand rested at %v, %v", p, ok)
+		}
+		if units := cave.Fill(SandAbyss); units != 23 {
+			t.Errorf("unexpected units %v", units)
+		}
+		want := strings.Join([]string{
+			"......+...",
+			"..........",
+			"......o...",
+			".....ooo..",
+			"....#ooo##",
+			"...o#ooo#.",
+			"..###ooo#.",
+			"....oooo#.",
+			".o.ooooo#.",
+			"#########.",
+		}, "\n") + "\n"
+		if render := cave.Render(SandAbyss); render != want {
+			t.Errorf("unexpected render\n%v", render)
+		}
+	})
+	t.Run("part 2 example", func(t *testing.T) {
+		t.Parallel()
+		cave, err := ParseCave(strings.NewReader(example))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if units := cave.Fill(SandFloor); units != 93 {
+			t.Errorf("unexpected units %v", units)
+		}
+		render := cave.Render(SandFloor)
+		rows := strings.Split(strings.TrimSuffix(render, "\n"), "\n")
+		if rows[0][len(rows[0])/2] != 'o' || strings.Trim(rows[len(rows)-1], "#") != "" {
+			t.Errorf("unexpected render\n%v", render)
+		}
+		if _, ok := cave.Drop(SandFloor); ok {
+			t.Errorf("expected the source to be blocked")
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		for _, in := range []string{"", "498,4 -> 499,5", "498,4 -> 498", "498,-1 -> 498,6", "a,b", "498,4 -> 498,6 junk", "498,4x -> 498,6"} {
+			if _, err := ParseCave(strings.NewReader(in)); err == nil {
+				t.Errorf("expected error for %q", in)
+			}
+		}
+	})
+}
+
+func TestDay15BeaconExclusionZone(t *testing.T) {
+	t.Parallel()
+	sensors, err := ParseSensors(MustOpen(t, 15, input.Example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("part 1 example", func(t *testing.T) {
+		t.Parallel()
+		if coverage := RowCoverage(sensors, 10); fmt.Sprint(coverage) != "[{-2 24}]" {
+			t.Errorf("unexpected coverage %v", coverage)
+		}
+		if excluded := ExcludedPositions(sensors, 10); excluded != 26 {
+			t.Errorf("unexpected excluded positions %v", excluded)
+		}
+	})
+	t.Run("part 2 example", func(t *testing.T) {
+		t.Parallel()
+		beacon, ok := FindDistressBeacon(sensors, 20)
+		if !ok || beacon != (Point{14, 11}) {
+			t.Fatalf("unexpected beacon %v, %v", beacon, ok)
+		}
+		if frequency := TuningFrequency(beacon); frequency != 56_000_011 {
+			t.Errorf("unexpected tuning frequency %v", frequency)
+		}
+		if _, ok := FindDistressBeacon(sensors[:1], 20); !ok {
+			t.Errorf("expected an uncovered position beside a single sensor")
+		}
+	})
+	t.Run("corner", func(t *testing.T) {
+		t.Parallel()
+		// One sensor covers everything but the corner at 0,0
+		corner := []Sensor{{Position: Point{6, 6}, Beacon: Point{6, 17}}}
+		if beacon, ok := FindDistressBeacon(corner, 10); !ok || beacon != (Point{0, 0}) {
+			t.Errorf("unexpected beacon %v, %v", beacon, ok)
+		}
+	})
+	t.Run("merge", func(t *testing.T) {
+		t.Parallel()
+		in := []Assignment{{5, 8}, {1, 2}, {3, 4}, {10, 12}, {6, 7}}
+		if merged := MergeAssignments(in); fmt.Sprint(merged) != "[{1 8} {10 12}]" {
+			t.Errorf("unexpected merge %v", merged)
+		}
+		if in[0] != (Assignment{5, 8}) {
+			t.Errorf("input was modified %v", in)
+		}
+		if MergeAssignments(nil) != nil {
+			t.Errorf("expected nothing to merge")
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		for _, in := range []string{
+			"Sensor at x=2, y=18",
+			"Sensor at x=2, y=18: closest beacon is at x=a, y=15",
+			"Sensor at x=2, y=18: closest beacon is at x=-2, y=15 extra",
+		} {
+			if _, err := ParseSensors(strings.NewReader(in)); err == nil {
+				t.Errorf("expected error for %q", in)
+			}
+		}
+	})
+}
+
+func TestDay16ProboscideaVolcanium(t *testing.T) {
+	t.Parallel()
+	valves, err := ParseValves(MustOpen(t, 16, input.Example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	network, err := CompressValves(valves, "AA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(network.Valves) != 7 || network.Valves[network.Start].Name != "AA" {
+		t.Fatalf("unexpected compressed valves %v", network.Valves)
+	}
+	// replay checks that plan's orders are walkable in time and release the pressure it claims
+	replay := func(t *testing.T, plan ValvePlan, minutes int) {
+		t.Helper()
+		index := map[string]int{}
+		for i, v := range network.Valves {
+			index[v.Name] = i
+		}
+		opened, pressure := NewSet[string](), 0
+		for _, order := range plan.Orders {
+			at, minute := network.Start, 0
+			for _, o := range order {
+				v := index[o.Valve]
+				minute += network.Dist[at][v] + 1
+				if o.Minute != minute || opened.Contains(o.Valve) {
+					t.Errorf("can't open %v in minute %v", o.Valve, o.Minute)
+				}
+				opened.Put(o.Valve)
+				pressure += network.Valves[v].Rate * (minutes - minute)
+				at = v
+			}
+		}
+		if pressure != plan.Pressure {
+			t.Errorf("orders %v release %v, not %v", plan.Orders, pressure, plan.Pressure)
+		}
+	}
+	t.Run("part 1 example", func(t *testing.T) {
+		t.Parallel()
+		plan := network.Plan(30, 1)
+		if plan.Pressure != 1651 {
+			t.Errorf("unexpected pressure %v", plan.Pressure)
+		}
+		want := "[[{DD 2} {BB 5} {JJ 9} {HH 17} {EE 21} {CC 24}]]"
+		if fmt.Sprint(plan.Orders) != want {
+			t.Errorf("unexpected orders %v", plan.Orders)
+		}
+		replay(t, plan, 30)
+	})
+	t.Run("part 2 example", func(t *testing.T) {
+		t.Parallel()
+		plan := network.Plan(26, 2)
+		if plan.Pressure != 1707 {
+			t.Errorf("unexpected pressure %v", plan.Pressure)
+		}
+		if len(plan.Orders) != 2 || len(plan.Orders[0])+len(plan.Orders[1]) != 6 {
+			t.Errorf("unexpected orders %v", plan.Orders)
+		}
+		replay(t, plan, 26)
+	})
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		for _, in := range []string{
+			"Valve AA has flow rate=0",
+			"Valve AA has flow rate=x; tunnel leads to valve AA",
+			"Valve AA has flow rate=-1; tunnel leads to valve AA",
+			"Valve AA has flow rate=0; tunnel leads to valve BB",
+			"Valve AA has flow rate=0; tunnels go to valve AA",
+			"Valve AA has flow rate=0; tunnel leads to valve AA\nValve AA has flow rate=0; tunnel leads to valve AA",
+		} {
+			if _, err := ParseValves(strings.NewReader(in)); err == nil {
+				t.Errorf("expected error for %q", in)
+			}
+		}
+		if _, err := CompressValves(valves, "ZZ"); err == nil {
+			t.Errorf("expected error for a missing start")
+		}
+		var many []Valve
+		for i := 0; i <= 64; i++ {
+			rate := 1
+			if i == 0 {
+				rate = 0
+			}
+			many = append(many, Valve{Name: fmt.Sprint("V", i), Rate: rate, Tunnels: []string{"V0"}})
+		}
+		if network, err := CompressValves(many, "V0"); err != nil || network.Start != 64 {
+			t.Errorf("expected 64 valves to open and a start outside them, got %v", err)
+		}
+		many = append(many, Valve{Name: "V65", Rate: 1, Tunnels: []string{"V0"}})
+		if _, err := CompressValves(many, "V0"); err == nil {
+			t.Errorf("expected error for 65 valves to open")
+		}
+	})
+}
+
+func TestDay17PyroclasticFlow(t *testing.T) {
+	t.Parallel()
+	jets, err := ParseJets(strings.NewReader(">>><<><>><<<>><>>><<<>>><<<><<<>><>><<>>\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jets) != 40 || jets[0] != Right || jets[3] != Left {
+		t.Fatalf("unexpected jets %v", jets)
+	}
+	t.Run("part 1 example", func(t *testing.T) {
+		t.Parallel()
+		if height, err := TowerHeight(jets, 2022); err != nil || height != 3068 {
+			t.Errorf("unexpected height %v, %v", height, err)
+		}
+	})
+	t.Run("part 2 example", func(t *testing.T) {
+		t.Parallel()
+		if height, err := TowerHeight(jets, 1_000_000_000_000); err != nil || height != 1_514_285_714_288 {
+			t.Errorf("unexpected height %v, %v", height, err)
+		}
+	})
+	t.Run("cycles match simulation", func(t *testing.T) {
+		t.Parallel()
+		c, err := NewChamber(jets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for rocks := 1; rocks <= 5000; rocks++ {
+			c.Drop()
+			if rocks > 200 && rocks%997 != 0 {
+				continue
+			}
+			if height, err := TowerHeight(jets, rocks); err != nil || height != c.Height() {
+				t.Errorf("skipping cycles to %v rocks got %v, %v, simulating got %v", rocks, height, err, c.Height())
+			}
+		}
+	})
+	t.Run("render", func(t *testing.T) {
+		t.Parallel()
+		c, err := NewChamber(jets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			c.Drop()
+		}
+		want := strings.Join([]string{
+			"|..#....|",
+			"|..#....|",
+			"|####...|",
+			"|..###..|",
+			"|...#...|",
+			"|..####.|",
+			"+-------+",
+		}, "\n") + "\n"
+		if c.String() != want {
+			t.Errorf("unexpected chamber\n%v", c)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		for _, in := range []string{"", "\n", "<>x<", "<>\n<"} {
+			if _, err := ParseJets(strings.NewReader(in)); err == nil {
+				t.Errorf("expected error for %q", in)
+			}
+		}
+		_, err := ParseJets(strings.NewReader("<>x<"))
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Column != 3 {
+			t.Errorf("expected syntax error at column 3, got %v", err)
+		}
+		for _, jets := range [][]Direction{nil, {Left, Up}} {
+			if _, err := NewChamber(jets); err == nil {
+				t.Errorf("expected error for jets %v", jets)
+			}
+			if _, err := TowerHeight(jets, 10); err == nil {
+				t.Errorf("expected error for jets %v", jets)
+			}
+		}
+		broken := errors.New("broken")
+		if _, err := ParseJets(io.MultiReader(strings.NewReader("<>"), iotest.ErrReader(broken))); !errors.Is(err, broken) {
+			t.Errorf("expected read error, got %v", err)
+		}
+		if _, err := StartOfPacketContext(context.Background(), iotest.ErrReader(broken)); !errors.Is(err, broken) {
+			t.Errorf("expected read error, got %v", err)
+		}
+	})
+}
+
+func TestDay18BoilingBoulders(t *testing.T) {
+	t.Parallel()
+	cubes, err := ParseCubes(MustOpen(t, 18, input.Example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("part 1 example", func(t *testing.T) {
+		t.Parallel()
+		if area := SurfaceArea(NewSet(Point3{1, 1, 1}, Point3{2, 1, 1})); area != 10 {
+			t.Errorf("unexpected area of two cubes %v", area)
+		}
+		if area := SurfaceArea(cubes); area != 64 {
+			t.Errorf("unexpected area %v", area)
+		}
+	})
+	t.Run("part 2 example", func(t *testing.T) {
+		t.Parallel()
+		if area := ExteriorSurfaceArea(cubes); area != 58 {
+			t.Errorf("unexpected area %v", area)
+		}
+		if area := ExteriorSurfaceArea(NewSet[Point3]()); area != 0 {
+			t.Errorf("unexpected area of nothing %v", area)
+		}
+	})
+	t.Run("hollow cube", func(t *testing.T) {
+		t.Parallel()
+		// A 3x3x3 cube with its center missing has the outside of a solid cube, plus the 6 faces of the pocket
+		hollow := NewSet[Point3]()
+		for x := 0; x < 3; x++ {
+			for y := 0; y < 3; y++ {
+				for z := 0; z < 3; z++ {
+					if (Point3{x, y, z}) != (Point3{1, 1, 1}) {
+						hollow.Put(Point3{x, y, z})
+					}
+				}
+			}
+		}
+		if area := SurfaceArea(hollow); area != 54+6 {
+			t.Errorf("unexpected area %v", area)
+		}
+		if area := ExteriorSurfaceArea(hollow); area != 54 {
+			t.Errorf("unexpected exterior area %v", area)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		for _, in := range []string{"1,2", "1,2,a", "1 2 3", "1,2,3,4", "1,2,3junk"} {
+			if _, err := ParseCubes(strings.NewReader(in)); err == nil {
+				t.Errorf("expected error for %q", in)
+			}
+		}
+	})
+}
+
+func TestTopK(t *testing.T) {
+	t.Parallel()
+	less := func(a, b int) bool { return a < b }
+	elems := []int{5, 1, 9, 3, 7, 9, 2}
+	if top := TopK(elems, 3, less); fmt.Sprint(top) != "[9 9 7]" {
+		t.Errorf("unexpected top 3 %v", top)
+	}
+	if top := TopK(elems, 10, less); fmt.Sprint(top) != "[9 9 7 5 3 2 1]" {
+		t.Errorf("unexpected top 10 %v", top)
+	}
+	if top := TopK(elems, 0, less); len(top) != 0 {
+		t.Errorf("unexpected top 0 %v", top)
+	}
+	if fmt.Sprint(elems) != "[5 1 9 3 7 9 2]" {
+		t.Errorf("input was modified %v", elems)
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	open := func(day int) io.Reader {
+		return MustOpen(t, day, input.Real)
+	}
+	grid, err := ParseGrid(open(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stacks, steps, err := ParseStacksAndSteps(open(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, run := range map[string]func() error{
+		"GetElves":           func() error { _, err := GetElvesContext(ctx, open(1)); return err },
+		"ParseStrategyGuide": func() error { _, err := ParseStrategyGuideContext(ctx, open(2)); return err },
+		"SumPriority":        func() error { _, err := SumPriorityContext(ctx, open(3)); return err },
+		"SumBadgePriority":   func() error { _, err := SumBadgePriorityContext(ctx, open(3)); return err },
+		"ParseAssignments":   func() error { _, err := ParseAssignmentsContext(ctx, open(4)); return err },
+		"ParseStacksAndSteps": func() error {
+			_, _, err := ParseStacksAndStepsContext(ctx, open(5))
+			return err
+		},
+		"ProcessSteps":         func() error { _, err := ProcessStepsContext(ctx, stacks, steps); return err },
+		"ProcessSteps9001":     func() error { _, err := ProcessSteps9001Context(ctx, stacks, steps); return err },
+		"StartOfPacket":        func() error { _, err := StartOfPacketContext(ctx, open(6)); return err },
+		"StartOfMessage":       func() error { _, err := StartOfMessageContext(ctx, open(6)); return err },
+		"ParseFS":              func() error { _, err := ParseFSContext(ctx, open(7)); return err },
+		"ParseGrid":            func() error { _, err := ParseGridContext(ctx, open(8)); return err },
+		"CountVisibleAndScore": func() error { _, _, err := CountVisibleAndScoreContext(ctx, grid); return err },
+	} {
+		if err := run(); !errors.Is(err, context.Canceled) {
+			t.Errorf("%v: expected context.Canceled, got %v", name, err)
+		}
+	}
+
+	// a stream with no marker would otherwise be read until EOF
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := StartOfMessageContext(ctx, endless{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// endless is a reader that never ends and never contains a start of packet or message
+type endless struct{}
+
+func (endless) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+	return len(p), nil
+}
+
+func FuzzGetElves(f *testing.F) {
+	f.Add([]byte("1000\n2000\n\n4000"))
+	f.Add(MustBytes(f, 1, input.Real))
+	f.Fuzz(func(t *testing.T, in []byte) {
+		elves, err := GetElves(bytes.NewReader(in))
+		if err == nil && len(elves) == 0 {
+			t.Errorf("expected at least one elf")
+		}
+	})
+}
+
+func FuzzParseStrategyGuide(f *testing.F) {
+	f.Add([]byte("A Y\nB X\nC Z"))
+	f.Add(MustBytes(f, 2, input.Real))
+	f.Fuzz(func(t *testing.T, in []byte) {
+		guide, err := ParseStrategyGuide(bytes.NewReader(in))
+		if err != nil {
+			return
+		}
+		CalculateRPSScore(guide, ModeSelf)
+		CalculateRPSScore(guide, ModeOutcome)
+	})
+}
+
+func FuzzSumPriority(f *testing.F) {
+	f.Add([]byte("vJrwpWtwJgWrhcsFMMfFFhFp\njqHRNqRjqzjGDLGLrsFMfFZSrLrFZsSL\nPmmdzqPrVvPwwTWBwg"))
+	f.Add(MustBytes(f, 3, input.Real))
+	f.Add([]byte("ab"))
+	f.Fuzz(func(t *testing.T, in []byte) {
+		SumPriority(bytes.NewReader(in))
+		SumBadgePriority(bytes.NewReader(in))
+	})
+}
+
+func FuzzParseAssignments(f *testing.F) {
+	f.Add([]byte("2-4,6-8\n2-3,4-5"))
+	f.Add(MustBytes(f, 4, input.Real))
+	f.Fuzz(func(t *testing.T, in []byte) {
+		assignments, err := ParseAssignments(bytes.NewReader(in))
+		if err != nil {
+			return
+		}
+		if full, overlapping := SumFullyOverlaps(assignments), SumOverlappingSections(assignments); full > overlapping {
+			t.Errorf("%v fully overlapping pairs but only %v overlapping", full, overlapping)
+		}
+	})
+}
+
+func FuzzParseStacksAndSteps(f *testing.F) {
+	f.Add(MustBytes(f, 5, input.Example))
+	f.Add(MustBytes(f, 5, input.Real))
+	f.Fuzz(func(t *testing.T, in []byte) {
+		stacks, steps, err := ParseStacksAndSteps(bytes.NewReader(in))
+		if err != nil {
+			return
+		}
+		SumTopOfStacks(ProcessSteps(stacks, steps))
+		// parse again, as processing moves the crates
+		stacks, steps, err = ParseStacksAndSteps(bytes.NewReader(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		SumTopOfStacks(ProcessSteps9001(stacks, steps))
+	})
+}
+
+func FuzzCommunicationDevice(f *testing.F) {
+	f.Add([]byte("mjqjpqmgbljsphdztnvjfqwrcgsmlb"), 4)
+	f.Add(MustBytes(f, 6, input.Real), 14)
+	f.Fuzz(func(t *testing.T, in []byte, length int) {
+		if length < 1 || length > 16 {
+			return
+		}
+		if start := communicationDevice(bytes.NewReader(in), length); start != -1 && (start < length || start > len(in)) {
+			t.Errorf("unexpected start %v", start)
+		}
+	})
+}
+
+func FuzzParseFS(f *testing.F) {
+	f.Add(MustBytes(f, 7, input.Example))
+	f.Add(MustBytes(f, 7, input.Real))
+	f.Add([]byte("$ cd /\n$ cd .."))
+	f.Add([]byte("$ cd /\n$ c"))
+	f.Fuzz(func(t *testing.T, in []byte) {
+		root, err := ParseFS(bytes.NewReader(in))
+		if err != nil {
+			return
+		}
+		var first, second bytes.Buffer
+		if err := WriteTranscript(&first, root); err != nil {
+			t.Fatal(err)
+		}
+		reparsed, err := ParseFS(bytes.NewReader(first.Bytes()))
+		if err != nil {
+			t.Fatalf("failed to parse transcript %q: %v", first.String(), err)
+		}
+		if err := WriteTranscript(&second, reparsed); err != nil {
+			t.Fatal(err)
+		}
+		if first.String() != second.String() {
+			t.Errorf("transcript did not round trip")
+		}
+	})
+}
+
+func FuzzParseGrid(f *testing.F) {
+	f.Add([]byte("30373\n25512\n65332\n33549\n35390"))
+	f.Add(MustBytes(f, 8, input.Real))
+	f.Fuzz(func(t *testing.T, in []byte) {
+		grid, err := ParseGrid(bytes.NewReader(in))
+		if err != nil {
+			return
+		}
+		if len(grid.Cells) != grid.Width*grid.Height {
+			t.Fatalf("%v cells in a %vx%v grid", len(grid.Cells), grid.Width, grid.Height)
+		}
+		visible, score := CountVisibleAndScore(grid)
+		if visible != VisibleTrees(grid).Len() {
+			t.Errorf("visible count %v does not match visible trees", visible)
+		}
+		if top := TopScenicTrees(grid, 1); top[0].Score != score {
+			t.Errorf("best score %v does not match top tree %v", score, top[0])
+		}
+	})
+}
+
+func FuzzParsePacket(f *testing.F) {
+	f.Add("[1,[2,[3,[4,[5,6,7]]]],8,9]")
+	f.Add("[[[]]]")
+	f.Add("[1,,2]")
+	f.Fuzz(func(t *testing.T, in string) {
+		packet, err := ParsePacket(in)
+		if err != nil {
+			return
+		}
+		reparsed, err := ParsePacket(packet.String())
+		if err != nil {
+			t.Fatalf("%q formatted as %q which does not parse: %v", in, packet, err)
+		}
+		if ComparePackets(packet, reparsed) != 0 || reparsed.String() != packet.String() {
+			t.Errorf("%v reparsed as %v", packet, reparsed)
+		}
+	})
 }
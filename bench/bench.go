@@ -0,0 +1,138 @@
+// Package bench records `go test -bench` results between runs and flags benchmarks that got slower
+package bench
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result is a single benchmark line
+type Result struct {
+	Name        string  `json:"name"`
+	NsPerOp     float64 `json:"ns_per_op"`
+	BytesPerOp  int64   `json:"bytes_per_op"`
+	AllocsPerOp int64   `json:"allocs_per_op"`
+}
+
+// Run is every result from a single invocation of the benchmarks
+type Run struct {
+	Time    time.Time `json:"time"`
+	Results []Result  `json:"results"`
+}
+
+// matches the name with its -GOMAXPROCS suffix removed, the iteration count, and the metrics
+var benchmarkLine = regexp.MustCompile(`^(Benchmark\S+?)(?:-\d+)?\s+\d+\s+(.*)$`)
+
+// Parse reads the output of `go test -bench`, ignoring anything that isn't a benchmark result
+func Parse(r io.Reader) ([]Result, error) {
+	var results []Result
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		match := benchmarkLine.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		result := Result{Name: match[1]}
+		fields := strings.Fields(match[2])
+		for i := 0; i+1 < len(fields); i += 2 {
+			value, unit := fields[i], fields[i+1]
+			var err error
+			switch unit {
+			case "ns/op":
+				result.NsPerOp, err = strconv.ParseFloat(value, 64)
+			case "B/op":
+				result.BytesPerOp, err = strconv.ParseInt(value, 10, 64)
+			case "allocs/op":
+				result.AllocsPerOp, err = strconv.ParseInt(value, 10, 64)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %v of %v: %w", unit, result.Name, err)
+			}
+		}
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// History is every recorded run, oldest first
+type History []Run
+
+func LoadFile(path string) (History, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var h History
+	if err := json.Unmarshal(b, &h); err != nil {
+		return nil, fmt.Errorf("failed to decode %v: %w", path, err)
+	}
+	return h, nil
+}
+
+func (h History) SaveFile(path string) error {
+	b, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// Latest returns the most recent run, if there is one
+func (h History) Latest() (Run, bool) {
+	if len(h) == 0 {
+		return Run{}, false
+	}
+	return h[len(h)-1], true
+}
+
+// Regression is a benchmark whose time per op grew by more than the threshold
+type Regression struct {
+	Name              string
+	Previous, Current float64
+}
+
+// Change is the fractional increase in time per op, e.g. 0.25 for 25% slower
+func (r Regression) Change() float64 {
+	return r.Current/r.Previous - 1
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%v: %.0f ns/op -> %.0f ns/op (%+.1f%%)", r.Name, r.Previous, r.Current, r.Change()*100)
+}
+
+// Compare returns the benchmarks in current that are slower than in previous by more than threshold, a fraction such
+// as 0.1 for 10%. Benchmarks missing from either run are ignored
+func Compare(previous, current []Result, threshold float64) []Regression {
+	before := make(map[string]float64, len(previous))
+	for _, r := range previous {
+		before[r.Name] = r.NsPerOp
+	}
+	var regressions []Regression
+	for _, r := range current {
+		prev, ok := before[r.Name]
+		if !ok || prev == 0 {
+			continue
+		}
+		if reg := (Regression{r.Name, prev, r.NsPerOp}); reg.Change() > threshold {
+			regressions = append(regressions, reg)
+		}
+	}
+	sort.Slice(regressions, func(i, j int) bool {
+		return regressions[i].Change() > regressions[j].Change()
+	})
+	return regressions
+}
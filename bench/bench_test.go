@@ -0,0 +1,76 @@
+package bench
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const output = `goos: linux
+goarch: amd64
+pkg: github.com/JeremyLoy/AdventOfCode2022
+cpu: Intel(R) Xeon(R) CPU
+BenchmarkGetElves-8              	   10000	    105234 ns/op	   54320 B/op	    2261 allocs/op
+BenchmarkParseFS/example-8       	  500000	      2301 ns/op
+BenchmarkCountVisibleAndScore    	    1000	   1002345.5 ns/op	  100 B/op	       3 allocs/op
+PASS
+ok  	github.com/JeremyLoy/AdventOfCode2022	5.123s
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	results, err := Parse(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Result{
+		{"BenchmarkGetElves", 105234, 54320, 2261},
+		{"BenchmarkParseFS/example", 2301, 0, 0},
+		{"BenchmarkCountVisibleAndScore", 1002345.5, 100, 3},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("unexpected result %+v, should have been %+v", results[i], want[i])
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+	previous := []Result{{Name: "A", NsPerOp: 100}, {Name: "B", NsPerOp: 100}, {Name: "C", NsPerOp: 100}}
+	current := []Result{{Name: "A", NsPerOp: 105}, {Name: "B", NsPerOp: 150}, {Name: "C", NsPerOp: 50}, {Name: "D", NsPerOp: 1}}
+	regressions := Compare(previous, current, 0.1)
+	if len(regressions) != 1 || regressions[0].Name != "B" {
+		t.Fatalf("unexpected regressions %v", regressions)
+	}
+	if change := regressions[0].Change(); change != 0.5 {
+		t.Errorf("unexpected change %v", change)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "bench.json")
+	h, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := h.Latest(); ok {
+		t.Errorf("expected empty history")
+	}
+	h = append(h, Run{Time: time.Unix(0, 0).UTC(), Results: []Result{{Name: "A", NsPerOp: 1}}})
+	if err := h.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	h, err = LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest, ok := h.Latest(); !ok || latest.Results[0].Name != "A" {
+		t.Errorf("unexpected history %+v", h)
+	}
+}
@@ -0,0 +1,182 @@
+// Command aoc runs the puzzle solutions against the answer ledger, submits new answers, and tracks benchmarks.
+//
+// Usage:
+//
+//	aoc run [-day N] [-ledger path]
+//	aoc submit -day N -part P [-ledger path]
+//	aoc bench [-bench regexp] [-out path] [-threshold fraction]
+//
+// Inputs are loaded with package input, so AOC_CACHE_DIR is honored. Submitting reads the session cookie from
+// AOC_SESSION, and records the verdict in the ledger file.
+//
+// Benchmarking runs `go test -bench` from the current directory, appends the results to the output file, and fails if
+// any benchmark got slower than in the previous run by more than the threshold
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	aoc "github.com/JeremyLoy/AdventOfCode2022"
+	"github.com/JeremyLoy/AdventOfCode2022/answer"
+	"github.com/JeremyLoy/AdventOfCode2022/bench"
+	"github.com/JeremyLoy/AdventOfCode2022/data"
+	"github.com/JeremyLoy/AdventOfCode2022/fetch"
+	"github.com/JeremyLoy/AdventOfCode2022/input"
+)
+
+const year = 2022
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "run":
+		err = run(os.Args[2:])
+	case "submit":
+		err = submit(os.Args[2:])
+	case "bench":
+		err = benchmark(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: aoc run|submit|bench [flags]")
+	os.Exit(2)
+}
+
+func ledgerFlag(fs *flag.FlagSet) *string {
+	return fs.String("ledger", filepath.Join("data", data.AnswersFile), "path to the answer ledger")
+}
+
+func solve(day, part int) (string, error) {
+	for _, puzzle := range aoc.Puzzles {
+		if puzzle.Day != day {
+			continue
+		}
+		b, err := input.Default().Bytes(day, input.Real)
+		if err != nil {
+			return "", err
+		}
+		return puzzle.Part(part)(bytes.NewReader(b))
+	}
+	return "", fmt.Errorf("day %v is not solved", day)
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	day := fs.Int("day", 0, "only run this day")
+	ledgerPath := ledgerFlag(fs)
+	fs.Parse(args)
+
+	ledger, err := answer.LoadFile(*ledgerPath)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, puzzle := range aoc.Puzzles {
+		if *day != 0 && puzzle.Day != *day {
+			continue
+		}
+		for part := 1; part <= 2; part++ {
+			got, err := solve(puzzle.Day, part)
+			if errors.Is(err, os.ErrNotExist) {
+				fmt.Printf("day %v part %v: no input\n", puzzle.Day, part)
+				continue
+			}
+			result := ledger.Check(puzzle.Day, part, got, err)
+			fmt.Println(result)
+			if result.Err != nil || (result.Want != "" && !result.OK()) {
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%v parts did not match the ledger", failed)
+	}
+	return nil
+}
+
+func submit(args []string) error {
+	fs := flag.NewFlagSet("submit", flag.ExitOnError)
+	day := fs.Int("day", 0, "day to submit")
+	part := fs.Int("part", 0, "part to submit, 1 or 2")
+	baseURL := fs.String("url", fetch.DefaultBaseURL, "server to submit to")
+	ledgerPath := ledgerFlag(fs)
+	fs.Parse(args)
+	if *day == 0 || (*part != 1 && *part != 2) {
+		return fmt.Errorf("-day and -part are required")
+	}
+
+	ledger, err := answer.LoadFile(*ledgerPath)
+	if err != nil {
+		return err
+	}
+	got, err := solve(*day, *part)
+	if err != nil {
+		return err
+	}
+	f := &fetch.Fetcher{BaseURL: *baseURL, Session: os.Getenv("AOC_SESSION"), Year: year}
+	verdict, err := f.Submit(context.Background(), ledger, *day, *part, got)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("day %v part %v: %v is %v\n", *day, *part, got, verdict)
+	return ledger.SaveFile(*ledgerPath)
+}
+
+func benchmark(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	pattern := fs.String("bench", ".", "run only benchmarks matching this regexp")
+	out := fs.String("out", "bench.json", "file to record results in")
+	threshold := fs.Float64("threshold", 0.1, "fractional slowdown that counts as a regression")
+	fs.Parse(args)
+
+	history, err := bench.LoadFile(*out)
+	if err != nil {
+		return err
+	}
+	var output bytes.Buffer
+	cmd := exec.Command("go", "test", "-run", "^$", "-bench", *pattern, "-benchmem", "./...")
+	cmd.Stdout = io.MultiWriter(os.Stdout, &output)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	results, err := bench.Parse(&output)
+	if err != nil {
+		return err
+	}
+	previous, hasPrevious := history.Latest()
+	history = append(history, bench.Run{Time: time.Now().UTC(), Results: results})
+	if err := history.SaveFile(*out); err != nil {
+		return err
+	}
+	if !hasPrevious {
+		return nil
+	}
+	regressions := bench.Compare(previous.Results, results, *threshold)
+	for _, r := range regressions {
+		fmt.Println("regression:", r)
+	}
+	if len(regressions) > 0 {
+		return fmt.Errorf("%v benchmarks regressed by more than %.0f%%", len(regressions), *threshold*100)
+	}
+	return nil
+}
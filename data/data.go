@@ -0,0 +1,11 @@
+// Package data embeds the puzzle inputs checked into the repository. Files are named dayN.txt for the real input and
+// dayN<variant>.txt for the example and any custom variants, see package input. AnswersFile is the ledger of known
+// answers, see package answer
+package data
+
+import "embed"
+
+const AnswersFile = "answers.json"
+
+//go:embed *.txt answers.json
+var FS embed.FS
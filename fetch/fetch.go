@@ -0,0 +1,245 @@
+// Package fetch downloads puzzle inputs and examples from an Advent of Code style server into the layout read by
+// package input, and submits answers to it
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JeremyLoy/AdventOfCode2022/answer"
+	"github.com/JeremyLoy/AdventOfCode2022/input"
+)
+
+const (
+	DefaultBaseURL = "https://adventofcode.com"
+	// DefaultInterval is the minimum time between requests to the server
+	DefaultInterval = 3 * time.Second
+)
+
+// Fetcher downloads inputs for a single year into Dir. Inputs already in Dir are never downloaded again
+type Fetcher struct {
+	// BaseURL defaults to [DefaultBaseURL]
+	BaseURL string
+	// Client defaults to [http.DefaultClient]
+	Client *http.Client
+	// Session is the value of the session cookie identifying the user
+	Session string
+	// UserAgent identifies the tool to the server operator, and should include contact details
+	UserAgent string
+	Year      int
+	Dir       string
+	// Interval defaults to [DefaultInterval]. Set it negative to disable rate limiting
+	Interval time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// Input downloads the real input for day, returning its local path
+func (f *Fetcher) Input(ctx context.Context, day int) (string, error) {
+	path := filepath.Join(f.Dir, input.FileName(day, input.Real))
+	if cached(path) {
+		return path, nil
+	}
+	b, err := f.get(ctx, fmt.Sprintf("/%d/day/%d/input", f.Year, day))
+	if err != nil {
+		return "", err
+	}
+	return path, f.write(path, b)
+}
+
+// Examples downloads the puzzle page for day and stores each example block in it. The first is stored as the
+// [input.Example] variant and later ones as example2, example3 and so on. It returns the local paths
+func (f *Fetcher) Examples(ctx context.Context, day int) ([]string, error) {
+	first := filepath.Join(f.Dir, input.FileName(day, input.Example))
+	if cached(first) {
+		return f.cachedExamples(day), nil
+	}
+	b, err := f.get(ctx, fmt.Sprintf("/%d/day/%d", f.Year, day))
+	if err != nil {
+		return nil, err
+	}
+	blocks := ExampleBlocks(string(b))
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no examples found for day %v", day)
+	}
+	var paths []string
+	for i, block := range blocks {
+		path := filepath.Join(f.Dir, input.FileName(day, exampleVariant(i)))
+		if err := f.write(path, []byte(block)); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func (f *Fetcher) cachedExamples(day int) []string {
+	var paths []string
+	for i := 0; ; i++ {
+		path := filepath.Join(f.Dir, input.FileName(day, exampleVariant(i)))
+		if !cached(path) {
+			return paths
+		}
+		paths = append(paths, path)
+	}
+}
+
+func exampleVariant(i int) input.Variant {
+	if i == 0 {
+		return input.Example
+	}
+	return input.Variant(fmt.Sprintf("%v%d", input.Example, i+1))
+}
+
+var codeBlock = regexp.MustCompile(`(?s)<pre><code>(.*?)</code></pre>`)
+var tag = regexp.MustCompile(`<[^>]*>`)
+
+// ExampleBlocks returns the text of every <pre><code> block in a puzzle page, with markup removed
+func ExampleBlocks(page string) []string {
+	var blocks []string
+	for _, match := range codeBlock.FindAllStringSubmatch(page, -1) {
+		blocks = append(blocks, html.UnescapeString(tag.ReplaceAllString(match[1], "")))
+	}
+	return blocks
+}
+
+func cached(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (f *Fetcher) write(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, input.Normalize(b), 0o644)
+}
+
+// wait blocks until Interval has passed since the previous request
+func (f *Fetcher) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	interval := f.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+	if delay := interval - time.Since(f.last); delay > 0 && !f.last.IsZero() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	f.last = time.Now()
+	return nil
+}
+
+func (f *Fetcher) get(ctx context.Context, path string) ([]byte, error) {
+	return f.do(ctx, http.MethodGet, path, nil)
+}
+
+func (f *Fetcher) do(ctx context.Context, method, path string, form url.Values) ([]byte, error) {
+	if f.Session == "" {
+		return nil, errors.New("missing session cookie")
+	}
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	base := f.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(base, "/")+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	req.AddCookie(&http.Cookie{Name: "session", Value: f.Session})
+	if f.UserAgent != "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%v %v: %v: %v", method, path, resp.Status, strings.TrimSpace(string(b)))
+	}
+	return b, nil
+}
+
+// Submit posts an answer for one part of a day's puzzle and returns the server's verdict. Answers already in ledger
+// are not resubmitted, and the verdict for new ones is recorded in it. ledger may be nil
+func (f *Fetcher) Submit(ctx context.Context, ledger *answer.Ledger, day, part int, ans string) (answer.Verdict, error) {
+	if ledger != nil {
+		if verdict, ok := ledger.Lookup(day, part, ans); ok {
+			return verdict, nil
+		}
+	}
+	form := url.Values{"level": {strconv.Itoa(part)}, "answer": {ans}}
+	b, err := f.do(ctx, http.MethodPost, fmt.Sprintf("/%d/day/%d/answer", f.Year, day), form)
+	if err != nil {
+		return "", err
+	}
+	verdict, err := ParseVerdict(string(b))
+	if err != nil {
+		return "", err
+	}
+	if ledger != nil {
+		ledger.Record(answer.Entry{Day: day, Part: part, Answer: ans, Verdict: verdict})
+	}
+	return verdict, nil
+}
+
+// ParseVerdict reads the verdict from the page returned after submitting an answer
+func ParseVerdict(page string) (answer.Verdict, error) {
+	switch {
+	case strings.Contains(page, "That's the right answer"):
+		return answer.Correct, nil
+	case strings.Contains(page, "You gave an answer too recently"):
+		return answer.RateLimited, nil
+	case strings.Contains(page, "You don't seem to be solving the right level"):
+		return answer.AlreadySolved, nil
+	case strings.Contains(page, "your answer is too high"):
+		return answer.TooHigh, nil
+	case strings.Contains(page, "your answer is too low"):
+		return answer.TooLow, nil
+	case strings.Contains(page, "That's not the right answer"):
+		return answer.Incorrect, nil
+	default:
+		return "", errors.New("unrecognized response to answer")
+	}
+}
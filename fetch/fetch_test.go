@@ -0,0 +1,192 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JeremyLoy/AdventOfCode2022/answer"
+	"github.com/JeremyLoy/AdventOfCode2022/input"
+)
+
+const page = `<article><p>For example:</p>
+<pre><code>R 4
+U 4
+</code></pre>
+<p>Then:</p>
+<pre><code>&lt;<em>R</em> 5&gt;
+</code></pre></article>`
+
+func newServer(t *testing.T, requests *int32) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2022/day/9/input", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if c, err := r.Cookie("session"); err != nil || c.Value != "secret" {
+			http.Error(w, "Puzzle inputs differ by user.  Please log in to get your puzzle input.", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("R 4\r\nU 4\r\n"))
+	})
+	mux.HandleFunc("/2022/day/9/answer", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		switch {
+		case r.Method != http.MethodPost || r.FormValue("level") != "1":
+			http.Error(w, "bad request", http.StatusBadRequest)
+		case r.FormValue("answer") == "13":
+			w.Write([]byte("<p>That's the right answer!</p>"))
+		default:
+			w.Write([]byte("<p>That's not the right answer; your answer is too low.</p>"))
+		}
+	})
+	mux.HandleFunc("/2022/day/9", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.Write([]byte(page))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetcher(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	server := newServer(t, &requests)
+	dir := t.TempDir()
+	f := &Fetcher{BaseURL: server.URL, Client: server.Client(), Session: "secret", Year: 2022, Dir: dir, Interval: -1}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		path, err := f.Input(ctx, 9)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path != filepath.Join(dir, "day9.txt") {
+			t.Errorf("unexpected path %v", path)
+		}
+		paths, err := f.Examples(ctx, 9)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(paths) != 2 {
+			t.Errorf("unexpected example paths %v", paths)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("cached inputs should not be downloaded again, got %v requests", requests)
+	}
+
+	loader := &input.Loader{CacheDir: dir}
+	for v, want := range map[input.Variant]string{
+		input.Real:                "R 4\nU 4",
+		input.Example:             "R 4\nU 4",
+		input.Variant("example2"): "<R 5>",
+	} {
+		b, err := loader.Bytes(9, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != want {
+			t.Errorf("%v: unexpected input %q", v, b)
+		}
+	}
+}
+
+func TestFetcherErrors(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	server := newServer(t, &requests)
+	f := &Fetcher{BaseURL: server.URL, Client: server.Client(), Session: "wrong", Year: 2022, Dir: t.TempDir(), Interval: -1}
+	if _, err := f.Input(context.Background(), 9); err == nil {
+		t.Errorf("expected error for bad session")
+	}
+	if _, err := os.Stat(filepath.Join(f.Dir, "day9.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("failed downloads should not be cached")
+	}
+	f.Session = ""
+	if _, err := f.Input(context.Background(), 9); err == nil {
+		t.Errorf("expected error for missing session")
+	}
+}
+
+func TestFetcherRateLimit(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	server := newServer(t, &requests)
+	f := &Fetcher{BaseURL: server.URL, Client: server.Client(), Session: "secret", Year: 2022, Dir: t.TempDir(), Interval: 50 * time.Millisecond}
+	start := time.Now()
+	if _, err := f.Input(context.Background(), 9); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Examples(context.Background(), 9); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < f.Interval {
+		t.Errorf("second request was not delayed, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f.Dir = t.TempDir()
+	if _, err := f.Input(ctx, 9); !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error waiting on a cancelled context %v", err)
+	}
+}
+
+func TestFetcherSubmit(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	server := newServer(t, &requests)
+	f := &Fetcher{BaseURL: server.URL, Client: server.Client(), Session: "secret", Year: 2022, Interval: -1}
+	ledger := new(answer.Ledger)
+	ctx := context.Background()
+	for _, tc := range []struct {
+		answer string
+		want   answer.Verdict
+	}{
+		{"12", answer.TooLow},
+		{"12", answer.TooLow},
+		{"13", answer.Correct},
+	} {
+		verdict, err := f.Submit(ctx, ledger, 9, 1, tc.answer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verdict != tc.want {
+			t.Errorf("%v: unexpected verdict %v", tc.answer, verdict)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("answers in the ledger should not be resubmitted, got %v requests", requests)
+	}
+	if want, ok := ledger.Correct(9, 1); !ok || want != "13" {
+		t.Errorf("unexpected correct answer %v", want)
+	}
+	if _, err := f.Submit(ctx, nil, 9, 2, "13"); err == nil {
+		t.Errorf("expected error for bad request")
+	}
+}
+
+func TestParseVerdict(t *testing.T) {
+	t.Parallel()
+	for page, want := range map[string]answer.Verdict{
+		"That's the right answer! You are one gold star closer":          answer.Correct,
+		"That's not the right answer; your answer is too high.":          answer.TooHigh,
+		"That's not the right answer. If you're stuck":                   answer.Incorrect,
+		"You gave an answer too recently; you have to wait":              answer.RateLimited,
+		"You don't seem to be solving the right level.  Did you already": answer.AlreadySolved,
+	} {
+		if verdict, err := ParseVerdict(page); err != nil || verdict != want {
+			t.Errorf("%q: unexpected verdict %v, %v", page, verdict, err)
+		}
+	}
+	if _, err := ParseVerdict("<html></html>"); err == nil {
+		t.Errorf("expected error for unrecognized page")
+	}
+}
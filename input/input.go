@@ -0,0 +1,166 @@
+// Package input resolves puzzle inputs by day and variant, so tests, benchmarks and the command line tools all load
+// them the same way
+package input
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/JeremyLoy/AdventOfCode2022/data"
+)
+
+// CacheDirEnv is the environment variable [Default] reads the local cache directory from
+const CacheDirEnv = "AOC_CACHE_DIR"
+
+// Variant distinguishes the inputs available for a single day. Anything other than [Real] and [Example] is a custom
+// variant, such as a hand written edge case
+type Variant string
+
+const (
+	Real    Variant = ""
+	Example Variant = "example"
+)
+
+func (v Variant) String() string {
+	if v == Real {
+		return "real"
+	}
+	return string(v)
+}
+
+// FileName returns the name an input is stored under, e.g. day7.txt or day7example.txt
+func FileName(day int, v Variant) string {
+	return fmt.Sprintf("day%d%s.txt", day, string(v))
+}
+
+// Loader looks up inputs first in CacheDir, if set, and then in FS
+type Loader struct {
+	// FS holds the inputs checked into the repository
+	FS fs.FS
+	// CacheDir is a local directory of inputs, such as those downloaded by package fetch. When Year is set, inputs are
+	// read from the Year subdirectory
+	CacheDir string
+	Year     int
+}
+
+// Default returns a Loader over the embedded data directory, with CacheDir read from [CacheDirEnv]
+func Default() *Loader {
+	return &Loader{FS: data.FS, CacheDir: os.Getenv(CacheDirEnv)}
+}
+
+// Dir returns the directory inputs are cached in, taking Year into account. It is empty if there is no cache
+func (l *Loader) Dir() string {
+	if l.CacheDir == "" || l.Year == 0 {
+		return l.CacheDir
+	}
+	return filepath.Join(l.CacheDir, strconv.Itoa(l.Year))
+}
+
+func (l *Loader) sources() []fs.FS {
+	var sources []fs.FS
+	if dir := l.Dir(); dir != "" {
+		sources = append(sources, os.DirFS(dir))
+	}
+	if l.FS != nil {
+		sources = append(sources, l.FS)
+	}
+	return sources
+}
+
+// Bytes returns the normalized contents of an input, see [Normalize]
+func (l *Loader) Bytes(day int, v Variant) ([]byte, error) {
+	name := FileName(day, v)
+	for _, source := range l.sources() {
+		b, err := fs.ReadFile(source, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return Normalize(b), nil
+	}
+	return nil, fmt.Errorf("no %v input for day %v: %w", v, day, fs.ErrNotExist)
+}
+
+func (l *Loader) Open(day int, v Variant) (io.Reader, error) {
+	b, err := l.Bytes(day, v)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// Days returns every day with an input of variant v, in ascending order
+func (l *Loader) Days(v Variant) ([]int, error) {
+	seen := make(map[int]bool)
+	for _, source := range l.sources() {
+		entries, err := fs.ReadDir(source, ".")
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if day, ok := parseFileName(entry.Name(), v); ok && !entry.IsDir() {
+				seen[day] = true
+			}
+		}
+	}
+	days := make([]int, 0, len(seen))
+	for day := range seen {
+		days = append(days, day)
+	}
+	sort.Ints(days)
+	return days, nil
+}
+
+// Years returns every year with a subdirectory in CacheDir, in ascending order
+func (l *Loader) Years() ([]int, error) {
+	if l.CacheDir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(l.CacheDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var years []int
+	for _, entry := range entries {
+		if year, err := strconv.Atoi(entry.Name()); err == nil && entry.IsDir() {
+			years = append(years, year)
+		}
+	}
+	sort.Ints(years)
+	return years, nil
+}
+
+func parseFileName(name string, v Variant) (int, bool) {
+	suffix := string(v) + ".txt"
+	if !strings.HasPrefix(name, "day") || !strings.HasSuffix(name, suffix) {
+		return 0, false
+	}
+	day, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "day"), suffix))
+	if err != nil || day < 1 {
+		return 0, false
+	}
+	return day, true
+}
+
+// Normalize converts CRLF and CR line endings to LF and removes trailing newlines
+func Normalize(b []byte) []byte {
+	b = bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+	b = bytes.ReplaceAll(b, []byte("\r"), []byte("\n"))
+	return bytes.TrimRight(b, "\n")
+}
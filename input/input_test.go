@@ -0,0 +1,97 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+	for in, want := range map[string]string{
+		"a\r\nb\r\n\r\n": "a\nb",
+		"a\rb\n":         "a\nb",
+		"a\n\nb":         "a\n\nb",
+		"":               "",
+	} {
+		if got := string(Normalize([]byte(in))); got != want {
+			t.Errorf("Normalize(%q) = %q, should have been %q", in, got, want)
+		}
+	}
+}
+
+func TestLoader(t *testing.T) {
+	t.Parallel()
+	cache := t.TempDir()
+	if err := os.Mkdir(filepath.Join(cache, "2022"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cache, "2022", FileName(9, Real)), []byte("R 4\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cache, "2022", FileName(1, Real)), []byte("cached\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loader := &Loader{
+		FS: fstest.MapFS{
+			"day1.txt":        {Data: []byte("1000\n")},
+			"day1example.txt": {Data: []byte("10\n")},
+			"day1edge.txt":    {Data: []byte("0\n")},
+			"day2.txt":        {Data: []byte("A Y\n")},
+		},
+		CacheDir: cache,
+		Year:     2022,
+	}
+	for _, tc := range []struct {
+		day  int
+		v    Variant
+		want string
+	}{
+		{1, Real, "cached"},
+		{1, Example, "10"},
+		{1, Variant("edge"), "0"},
+		{2, Real, "A Y"},
+		{9, Real, "R 4"},
+	} {
+		b, err := loader.Bytes(tc.day, tc.v)
+		if err != nil {
+			t.Errorf("day %v %v: %v", tc.day, tc.v, err)
+			continue
+		}
+		if string(b) != tc.want {
+			t.Errorf("day %v %v: unexpected input %q", tc.day, tc.v, b)
+		}
+	}
+	if _, err := loader.Bytes(3, Real); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("unexpected error for missing input %v", err)
+	}
+	days, err := loader.Days(Real)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(days) != "[1 2 9]" {
+		t.Errorf("unexpected days %v", days)
+	}
+	years, err := loader.Years()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(years) != "[2022]" {
+		t.Errorf("unexpected years %v", years)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	t.Setenv(CacheDirEnv, "")
+	days, err := Default().Days(Example)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) < 2 || days[0] != 5 || days[1] != 7 {
+		t.Errorf("unexpected example days %v", days)
+	}
+}